@@ -1,85 +1,131 @@
 package telegraph
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
 // CreateAccount creates a new Telegraph account
 // See https://telegra.ph/api#createAccount
 func (c *Client) CreateAccount(shortName, authorName, authorURL string) (*Account, error) {
-	account := &Account{
+	return c.CreateAccountWith(context.Background(), CreateAccountRequest{
 		ShortName:  shortName,
 		AuthorName: authorName,
 		AuthorURL:  authorURL,
-	}
+	})
+}
 
-	var result CreateAccountResponse
-	if err := c.doRequest("POST", "createAccount", account, &result); err != nil {
-		return nil, fmt.Errorf("failed to create account: %w", err)
-	}
+// CreateAccountWith creates a new Telegraph account
+// See https://telegra.ph/api#createAccount
+func (c *Client) CreateAccountWith(ctx context.Context, req CreateAccountRequest) (*Account, error) {
+	return call[Account](ctx, c, "createAccount", req, ErrCreateAccountFailed)
+}
 
-	if !result.Ok {
-		return nil, ErrCreateAccountFailed
+// GetAccountInfo retrieves information about a Telegraph account.
+// Fields must be names of AccountField values.
+// See https://telegra.ph/api#getAccountInfo
+func (c *Client) GetAccountInfo(accessToken string, fields []string) (*Account, error) {
+	typed := make([]AccountField, len(fields))
+	for i, field := range fields {
+		typed[i] = AccountField(field)
 	}
+	return c.GetAccountInfoWith(context.Background(), GetAccountInfoRequest{AccessToken: accessToken, Fields: typed})
+}
 
-	return &result.Result, nil
+// GetAccountFields retrieves the given fields of a Telegraph account.
+// When no fields are given the API returns short_name, author_name and author_url.
+// See https://telegra.ph/api#getAccountInfo
+func (c *Client) GetAccountFields(accessToken string, fields ...AccountField) (*Account, error) {
+	return c.GetAccountInfoWith(context.Background(), GetAccountInfoRequest{AccessToken: accessToken, Fields: fields})
 }
 
-// GetAccountInfo retrieves information about a Telegraph account
+// GetAccountInfoWith retrieves information about a Telegraph account
 // See https://telegra.ph/api#getAccountInfo
-func (c *Client) GetAccountInfo(accessToken string, fields []string) (*Account, error) {
-	body := map[string]interface{}{
-		"access_token": accessToken,
-		"fields":       fields,
+func (c *Client) GetAccountInfoWith(ctx context.Context, req GetAccountInfoRequest) (*Account, error) {
+	for _, field := range req.Fields {
+		if !field.Valid() {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidAccountField, field)
+		}
 	}
 
-	var result GetAccountInfoResponse
-	if err := c.doRequest("POST", "getAccountInfo", body, &result); err != nil {
-		return nil, fmt.Errorf("failed to get account info: %w", err)
-	}
+	return call[Account](ctx, c, "getAccountInfo", req, ErrGetAccountInfoFailed)
+}
 
-	if !result.Ok {
-		return nil, ErrGetAccountInfoFailed
+// AuthURL returns a link that logs the user into the account in a browser.
+// The link is valid for one use within 5 minutes.
+func (c *Client) AuthURL(accessToken string) (string, error) {
+	account, err := c.GetAccountFields(accessToken, FieldAuthURL)
+	if err != nil {
+		return "", err
 	}
-
-	return &result.Result, nil
+	if account.AuthURL == "" {
+		return "", fmt.Errorf("%w: no auth_url in response", ErrGetAccountInfoFailed)
+	}
+	return account.AuthURL, nil
 }
 
-// EditAccountInfo edits information of a Telegraph account
+// EditAccountInfo edits information of a Telegraph account.
+// All fields are sent, so empty values clear them; use PatchAccountInfo to
+// change only some of them.
 // See https://telegra.ph/api#editAccountInfo
 func (c *Client) EditAccountInfo(accessToken, shortName, authorName, authorURL string) (*Account, error) {
-	body := map[string]interface{}{
-		"access_token": accessToken,
-		"short_name":   shortName,
-		"author_name":  authorName,
-		"author_url":   authorURL,
-	}
+	return c.PatchAccountInfo(accessToken, AccountPatch{
+		ShortName:  String(shortName),
+		AuthorName: String(authorName),
+		AuthorURL:  String(authorURL),
+	})
+}
 
-	var result EditAccountInfoResponse
-	if err := c.doRequest("POST", "editAccountInfo", body, &result); err != nil {
-		return nil, fmt.Errorf("failed to edit account info: %w", err)
+// PatchAccountInfo changes the fields of a Telegraph account set in patch
+// and leaves the others as they are
+// See https://telegra.ph/api#editAccountInfo
+func (c *Client) PatchAccountInfo(accessToken string, patch AccountPatch) (*Account, error) {
+	return c.EditAccountInfoWith(context.Background(), EditAccountInfoRequest{AccessToken: accessToken, AccountPatch: patch})
+}
+
+// EditAccountInfoWith edits information of a Telegraph account
+// See https://telegra.ph/api#editAccountInfo
+func (c *Client) EditAccountInfoWith(ctx context.Context, req EditAccountInfoRequest) (*Account, error) {
+	return call[Account](ctx, c, "editAccountInfo", req, ErrEditAccountInfoFailed)
+}
+
+// UpdateAccountInfo fetches the current account information, lets update
+// modify it and sends the fields that changed. When nothing changed no edit
+// is made and the current information is returned.
+func (c *Client) UpdateAccountInfo(accessToken string, update func(*Account)) (*Account, error) {
+	current, err := c.GetAccountFields(accessToken, FieldShortName, FieldAuthorName, FieldAuthorURL)
+	if err != nil {
+		return nil, err
 	}
 
-	if !result.Ok {
-		return nil, ErrEditAccountInfoFailed
+	updated := *current
+	update(&updated)
+
+	var patch AccountPatch
+	if updated.ShortName != current.ShortName {
+		patch.ShortName = String(updated.ShortName)
+	}
+	if updated.AuthorName != current.AuthorName {
+		patch.AuthorName = String(updated.AuthorName)
+	}
+	if updated.AuthorURL != current.AuthorURL {
+		patch.AuthorURL = String(updated.AuthorURL)
+	}
+	if patch == (AccountPatch{}) {
+		return current, nil
 	}
 
-	return &result.Result, nil
+	return c.PatchAccountInfo(accessToken, patch)
 }
 
 // RevokeAccessToken revokes an access token for a Telegraph account
 // See https://telegra.ph/api#revokeAccessToken
 func (c *Client) RevokeAccessToken(accessToken string) (*Account, error) {
-	body := map[string]interface{}{
-		"access_token": accessToken,
-	}
-
-	var result RevokeAccessTokenResponse
-	if err := c.doRequest("POST", "revokeAccessToken", body, &result); err != nil {
-		return nil, fmt.Errorf("failed to revoke access token: %w", err)
-	}
-
-	if !result.Ok {
-		return nil, ErrRevokeAccessTokenFailed
-	}
+	return c.RevokeAccessTokenWith(context.Background(), RevokeAccessTokenRequest{AccessToken: accessToken})
+}
 
-	return &result.Result, nil
+// RevokeAccessTokenWith revokes an access token for a Telegraph account
+// See https://telegra.ph/api#revokeAccessToken
+func (c *Client) RevokeAccessTokenWith(ctx context.Context, req RevokeAccessTokenRequest) (*Account, error) {
+	return call[Account](ctx, c, "revokeAccessToken", req, ErrRevokeAccessTokenFailed)
 }
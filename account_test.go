@@ -1,8 +1,12 @@
 package telegraph_test
 
 import (
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/smirnoffmg/telegraph"
@@ -29,6 +33,34 @@ func mockServer(response string, statusCode int) *httptest.Server {
 	return httptest.NewServer(mux)
 }
 
+type recordedRequest struct {
+	Method string
+	Body   map[string]interface{}
+}
+
+// recordingServer answers every request with the response registered for
+// its API method and records the decoded request bodies
+func recordingServer(t *testing.T, responses map[string]string) (*httptest.Server, *[]recordedRequest) {
+	var requests []recordedRequest
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		method := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)[0]
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+		requests = append(requests, recordedRequest{Method: method, Body: body})
+
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write([]byte(responses[method])); err != nil {
+			http.Error(w, "Failed to write response", http.StatusInternalServerError)
+		}
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
 func TestCreateAccount(t *testing.T) {
 	server := mockServer(testAccountResponse, http.StatusOK)
 	defer server.Close()
@@ -140,3 +172,80 @@ func TestRevokeAccessToken(t *testing.T) {
 		t.Fatalf("Expected error, got nil")
 	}
 }
+
+func TestGetAccountFields(t *testing.T) {
+	server := mockServer(`{"ok":true,"result":{"short_name":"Test","auth_url":"https://edit.telegra.ph/auth/abc"}}`, http.StatusOK)
+	defer server.Close()
+
+	client := telegraph.NewClient(server.Client())
+	client.SetBaseURL(server.URL + "/")
+
+	account, err := client.GetAccountFields(accessToken, telegraph.FieldShortName, telegraph.FieldAuthURL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if account.AuthURL != "https://edit.telegra.ph/auth/abc" {
+		t.Errorf("Expected AuthURL to be set, got '%s'", account.AuthURL)
+	}
+
+	authURL, err := client.AuthURL(accessToken)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if authURL != account.AuthURL {
+		t.Errorf("Expected AuthURL '%s', got '%s'", account.AuthURL, authURL)
+	}
+
+	// Test invalid field
+	_, err = client.GetAccountInfo(accessToken, []string{"short_name", "password"})
+	if !errors.Is(err, telegraph.ErrInvalidAccountField) {
+		t.Errorf("Expected ErrInvalidAccountField, got %v", err)
+	}
+}
+
+func TestPatchAccountInfo(t *testing.T) {
+	server, requests := recordingServer(t, map[string]string{
+		"getAccountInfo":  testAccountResponse,
+		"editAccountInfo": testAccountResponse,
+	})
+
+	client := telegraph.NewClient(server.Client())
+	client.SetBaseURL(server.URL + "/")
+
+	_, err := client.PatchAccountInfo(accessToken, telegraph.AccountPatch{AuthorName: telegraph.String("New Name")})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	body := (*requests)[0].Body
+	if body["author_name"] != "New Name" || body["access_token"] != accessToken {
+		t.Errorf("Expected author_name and access_token to be sent, got %v", body)
+	}
+	if _, ok := body["author_url"]; ok {
+		t.Errorf("Expected author_url not to be sent, got %v", body)
+	}
+
+	// Unchanged fields are not sent by UpdateAccountInfo
+	*requests = nil
+	_, err = client.UpdateAccountInfo(accessToken, func(a *telegraph.Account) {
+		a.AuthorURL = "https://example.org"
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(*requests) != 2 || (*requests)[0].Method != "getAccountInfo" {
+		t.Fatalf("Expected account info to be fetched and edited, got %v", *requests)
+	}
+	body = (*requests)[1].Body
+	if body["author_url"] != "https://example.org" || body["short_name"] != nil || body["author_name"] != nil {
+		t.Errorf("Expected only author_url to be sent, got %v", body)
+	}
+
+	// Nothing is sent when nothing changed
+	*requests = nil
+	if _, err := client.UpdateAccountInfo(accessToken, func(*telegraph.Account) {}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(*requests) != 1 {
+		t.Errorf("Expected no edit request, got %v", *requests)
+	}
+}
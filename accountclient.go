@@ -0,0 +1,206 @@
+package telegraph
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// AccountClient is a client bound to the access token of a single account.
+// It exposes the account and page methods of Client without the access
+// token argument and is safe for concurrent use.
+type AccountClient struct {
+	client  *Client
+	manager *AccountManager // manager that handed out the client, if any
+	name    string
+	mu      sync.RWMutex
+	token   string
+}
+
+// WithToken returns a client that performs requests on behalf of the account
+// with the given access token. The returned client shares c's settings.
+func (c *Client) WithToken(accessToken string) *AccountClient {
+	return &AccountClient{client: c, token: accessToken}
+}
+
+// Name returns the name the account is stored under in an AccountManager,
+// or an empty string for clients created with WithToken
+func (a *AccountClient) Name() string {
+	return a.name
+}
+
+// Token returns the current access token of the account
+func (a *AccountClient) Token() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.token
+}
+
+// GetAccountInfo retrieves information about the account
+func (a *AccountClient) GetAccountInfo(fields []string) (*Account, error) {
+	return a.client.GetAccountInfo(a.Token(), fields)
+}
+
+// GetAccountFields retrieves the given fields of the account
+func (a *AccountClient) GetAccountFields(fields ...AccountField) (*Account, error) {
+	return a.client.GetAccountFields(a.Token(), fields...)
+}
+
+// AuthURL returns a link that logs the user into the account in a browser
+func (a *AccountClient) AuthURL() (string, error) {
+	return a.client.AuthURL(a.Token())
+}
+
+// GetAccountInfoWith retrieves information about the account.
+// The access token of the request is set to the account's token.
+func (a *AccountClient) GetAccountInfoWith(ctx context.Context, req GetAccountInfoRequest) (*Account, error) {
+	req.AccessToken = a.Token()
+	return a.client.GetAccountInfoWith(ctx, req)
+}
+
+// EditAccountInfo edits information of the account
+func (a *AccountClient) EditAccountInfo(shortName, authorName, authorURL string) (*Account, error) {
+	return a.client.EditAccountInfo(a.Token(), shortName, authorName, authorURL)
+}
+
+// EditAccountInfoWith edits information of the account.
+// The access token of the request is set to the account's token.
+func (a *AccountClient) EditAccountInfoWith(ctx context.Context, req EditAccountInfoRequest) (*Account, error) {
+	req.AccessToken = a.Token()
+	return a.client.EditAccountInfoWith(ctx, req)
+}
+
+// PatchAccountInfo changes the account fields set in patch
+func (a *AccountClient) PatchAccountInfo(patch AccountPatch) (*Account, error) {
+	return a.client.PatchAccountInfo(a.Token(), patch)
+}
+
+// UpdateAccountInfo changes the account information with a read-modify-write cycle
+func (a *AccountClient) UpdateAccountInfo(update func(*Account)) (*Account, error) {
+	return a.client.UpdateAccountInfo(a.Token(), update)
+}
+
+// RevokeAccessToken revokes the access token of the account and switches
+// the client to the new one. Calls made through the client while the token
+// is being revoked wait for it and use the new token. For clients handed out
+// by an AccountManager the new token is saved like RotateToken does.
+func (a *AccountClient) RevokeAccessToken() (*Account, error) {
+	if a.manager != nil {
+		return a.manager.rotate(a)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.revokeLocked()
+}
+
+// revokeLocked revokes the token and switches to the new one; a.mu must be held
+func (a *AccountClient) revokeLocked() (*Account, error) {
+	account, err := a.client.RevokeAccessToken(a.token)
+	if err != nil {
+		return nil, err
+	}
+	if account.AccessToken == "" {
+		return nil, fmt.Errorf("%w: no access token in response", ErrRevokeAccessTokenFailed)
+	}
+	a.token = account.AccessToken
+	return account, nil
+}
+
+// CreatePage creates a new page owned by the account
+func (a *AccountClient) CreatePage(title string, content []Node, authorName, authorURL string) (*Page, error) {
+	return a.client.CreatePage(a.Token(), title, content, authorName, authorURL)
+}
+
+// CreatePageWith creates a new page owned by the account.
+// The access token of the request is set to the account's token.
+func (a *AccountClient) CreatePageWith(ctx context.Context, req CreatePageRequest) (*Page, error) {
+	req.AccessToken = a.Token()
+	return a.client.CreatePageWith(ctx, req)
+}
+
+// CreatePageFromHTML creates a new page owned by the account from HTML
+func (a *AccountClient) CreatePageFromHTML(title, htmlContent, authorName, authorURL string) (*Page, error) {
+	return a.client.CreatePageFromHTML(a.Token(), title, htmlContent, authorName, authorURL)
+}
+
+// PublishSeries publishes content as a series of pages owned by the account
+func (a *AccountClient) PublishSeries(title string, content []Node, authorName, authorURL string) ([]*Page, error) {
+	return a.client.PublishSeries(a.Token(), title, content, authorName, authorURL)
+}
+
+// EditPage edits an existing page owned by the account
+func (a *AccountClient) EditPage(path, title string, content []Node, authorName, authorURL string) (*Page, error) {
+	return a.client.EditPage(a.Token(), path, title, content, authorName, authorURL)
+}
+
+// EditPageWith edits an existing page owned by the account.
+// The access token of the request is set to the account's token.
+func (a *AccountClient) EditPageWith(ctx context.Context, req EditPageRequest) (*Page, error) {
+	req.AccessToken = a.Token()
+	return a.client.EditPageWith(ctx, req)
+}
+
+// PatchPage changes the fields set in patch of a page owned by the account
+func (a *AccountClient) PatchPage(path string, patch PagePatch) (*Page, error) {
+	return a.client.PatchPage(a.Token(), path, patch)
+}
+
+// UpdatePage changes a page owned by the account with a read-modify-write cycle
+func (a *AccountClient) UpdatePage(path string, update func(*Page)) (*Page, error) {
+	return a.client.UpdatePage(a.Token(), path, update)
+}
+
+// GetPageList retrieves a list of pages owned by the account
+func (a *AccountClient) GetPageList(offset, limit int) (*PageList, error) {
+	return a.client.GetPageList(a.Token(), offset, limit)
+}
+
+// GetPageListWith retrieves a list of pages owned by the account.
+// The access token of the request is set to the account's token.
+func (a *AccountClient) GetPageListWith(ctx context.Context, req GetPageListRequest) (*PageList, error) {
+	req.AccessToken = a.Token()
+	return a.client.GetPageListWith(ctx, req)
+}
+
+// GetAllPages retrieves all pages owned by the account
+func (a *AccountClient) GetAllPages(ctx context.Context) ([]Page, error) {
+	return a.client.GetAllPages(ctx, a.Token())
+}
+
+// GetPage retrieves a page from Telegraph
+func (a *AccountClient) GetPage(path string, returnContent bool) (*Page, error) {
+	return a.client.GetPage(path, returnContent)
+}
+
+// GetViews retrieves the number of views for a page on Telegraph
+func (a *AccountClient) GetViews(path string, year, month, day int) (*PageViews, error) {
+	return a.client.GetViews(path, year, month, day)
+}
+
+// Backup saves all pages of the account to the archive in dir. See
+// Client.Backup.
+func (a *AccountClient) Backup(ctx context.Context, dir string) (*BackupIndex, error) {
+	return a.client.Backup(ctx, a.Token(), dir)
+}
+
+// Restore republishes the pages of the backup archive in dir into the
+// account. See Client.Restore.
+func (a *AccountClient) Restore(ctx context.Context, dir string, done map[string]string,
+	copied func(from, to string) error,
+) (map[string]string, error) {
+	return a.client.Restore(ctx, a.Token(), dir, done, copied)
+}
+
+// Migrate republishes all pages of the account with the access token
+// fromToken into this account. See Client.Migrate.
+func (a *AccountClient) Migrate(ctx context.Context, fromToken string, done map[string]string,
+	copied func(from, to string) error,
+) (map[string]string, error) {
+	return a.client.Migrate(ctx, fromToken, a.Token(), done, copied)
+}
+
+// ExportSite writes a static website mirroring all pages of the account to
+// dir. See Client.ExportSite.
+func (a *AccountClient) ExportSite(ctx context.Context, dir string, opts SiteOptions) ([]SitePage, error) {
+	return a.client.ExportSite(ctx, a.Token(), dir, opts)
+}
@@ -0,0 +1,49 @@
+package telegraph_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/smirnoffmg/telegraph"
+)
+
+func TestWithToken(t *testing.T) {
+	server, requests := recordingServer(t, map[string]string{
+		"createPage":        testPageResponse,
+		"getPageList":       testPageListResponse,
+		"revokeAccessToken": `{"ok":true,"result":{"access_token":"654321","auth_url":"https://edit.telegra.ph/auth/abc"}}`,
+	})
+
+	client := telegraph.NewClient(server.Client())
+	client.SetBaseURL(server.URL + "/")
+	ac := client.WithToken(accessToken)
+
+	content := []telegraph.Node{telegraph.NodeElement{Tag: "p", Children: []telegraph.Node{"Hello"}}}
+	if _, err := ac.CreatePage(title, content, authorName, authorURL); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := ac.GetPageListWith(context.Background(), telegraph.GetPageListRequest{AccessToken: "ignored", Limit: 10}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, req := range *requests {
+		if req.Body["access_token"] != accessToken {
+			t.Errorf("Expected %s to use token '%s', got %v", req.Method, accessToken, req.Body["access_token"])
+		}
+	}
+
+	account, err := ac.RevokeAccessToken()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if ac.Token() != "654321" || account.AccessToken != "654321" {
+		t.Errorf("Expected client to switch to the new token, got '%s'", ac.Token())
+	}
+
+	*requests = nil
+	if _, err := ac.GetPageList(0, 10); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if (*requests)[0].Body["access_token"] != "654321" {
+		t.Errorf("Expected new token to be used, got %v", (*requests)[0].Body["access_token"])
+	}
+}
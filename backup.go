@@ -0,0 +1,262 @@
+package telegraph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// BackupVersion is the version of the archive format written by Backup
+const BackupVersion = 1
+
+// Files and directories of a backup archive
+const (
+	backupIndexFile = "index.json"
+	backupPagesDir  = "pages"
+	backupAssetsDir = "assets"
+)
+
+// errFileNotFound is returned by download for files that do not exist
+var errFileNotFound = errors.New("file not found")
+
+// telegraphHosts are the hosts files uploaded to Telegraph are served from
+var telegraphHosts = map[string]struct{}{
+	"telegra.ph":  {},
+	"te.legra.ph": {},
+}
+
+// BackupIndex describes a backup archive. It is kept in index.json in the
+// archive directory, next to a JSON file per page in pages/ and the
+// downloaded files in assets/.
+type BackupIndex struct {
+	Version  int       `json:"version"`
+	Started  time.Time `json:"started"`
+	Complete bool      `json:"complete"` // false while the backup is running or if it was interrupted
+	// Pages lists the backed up pages, newest first
+	Pages []BackupPage `json:"pages"`
+	// Assets maps the src of every downloaded file, as found in the pages,
+	// to its path in the archive
+	Assets map[string]string `json:"assets"`
+}
+
+// BackupPage is a page in a backup archive
+type BackupPage struct {
+	Path  string `json:"path"`
+	URL   string `json:"url"`
+	Title string `json:"title"`
+	File  string `json:"file"` // path of the page JSON in the archive
+}
+
+// ReadBackup reads the index of the backup archive in dir
+func ReadBackup(dir string) (*BackupIndex, error) {
+	data, err := os.ReadFile(filepath.Join(dir, backupIndexFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup: %w", err)
+	}
+	var index BackupIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to decode backup index: %w", err)
+	}
+	if index.Version != BackupVersion {
+		return nil, fmt.Errorf("unsupported backup version %d", index.Version)
+	}
+	if index.Assets == nil {
+		index.Assets = make(map[string]string)
+	}
+	return &index, nil
+}
+
+// Backup saves all pages of the account with their content to the archive
+// in dir, along with the files uploaded to Telegraph that they show.
+// The index is updated after every page, so a backup that was interrupted
+// is resumed by calling Backup again: pages already saved are not fetched
+// again. Once a backup is complete, the next one fetches all pages anew but
+// still skips files that were downloaded before.
+func (c *Client) Backup(ctx context.Context, accessToken, dir string) (*BackupIndex, error) {
+	index, err := ReadBackup(dir)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		index = &BackupIndex{Assets: make(map[string]string)}
+	case err != nil:
+		return nil, err
+	}
+
+	saved := make(map[string]BackupPage)
+	if !index.Complete {
+		for _, page := range index.Pages {
+			if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(page.File))); err == nil {
+				saved[page.Path] = page
+			}
+		}
+	}
+	if index.Complete || index.Started.IsZero() {
+		index.Started = time.Now().UTC()
+	}
+	index.Version = BackupVersion
+	index.Complete = false
+	index.Pages = nil
+
+	pages, err := c.GetAllPages(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	for _, listed := range pages {
+		entry, ok := saved[listed.Path]
+		if !ok {
+			if entry, err = c.backupPage(ctx, dir, index, listed.Path); err != nil {
+				return nil, err
+			}
+		}
+		index.Pages = append(index.Pages, entry)
+		if err := writeBackupIndex(dir, index); err != nil {
+			return nil, err
+		}
+	}
+
+	index.Complete = true
+	if err := writeBackupIndex(dir, index); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+// backupPage fetches the page at pagePath, downloads its files and writes
+// it to the archive
+func (c *Client) backupPage(ctx context.Context, dir string, index *BackupIndex, pagePath string) (BackupPage, error) {
+	page, err := c.GetPageWith(ctx, GetPageRequest{Path: pagePath, ReturnContent: true})
+	if err != nil {
+		return BackupPage{}, fmt.Errorf("failed to back up %s: %w", pagePath, err)
+	}
+
+	for _, src := range mediaSources(page.Content) {
+		if _, ok := index.Assets[src]; ok {
+			continue
+		}
+		file, err := c.downloadAsset(ctx, dir, src)
+		if err != nil {
+			return BackupPage{}, fmt.Errorf("failed to back up %s: %w", pagePath, err)
+		}
+		if file != "" {
+			index.Assets[src] = file
+		}
+	}
+
+	data, err := json.MarshalIndent(page, "", "  ")
+	if err != nil {
+		return BackupPage{}, fmt.Errorf("failed to encode page %s: %w", pagePath, err)
+	}
+	file := path.Join(backupPagesDir, url.PathEscape(pagePath)+".json")
+	if err := WriteFileAtomic(filepath.Join(dir, filepath.FromSlash(file)), data); err != nil {
+		return BackupPage{}, err
+	}
+	return BackupPage{Path: pagePath, URL: page.URL, Title: page.Title, File: file}, nil
+}
+
+// downloadAsset downloads the Telegraph file src refers to into the assets
+// of the archive and returns its path in the archive. Files that are not on
+// Telegraph and files that no longer exist are skipped with an empty path.
+func (c *Client) downloadAsset(ctx context.Context, dir, src string) (string, error) {
+	u, ok := c.fileURL(src)
+	if !ok {
+		return "", nil
+	}
+
+	file := path.Join(backupAssetsDir, path.Base(u.Path))
+	target := filepath.Join(dir, filepath.FromSlash(file))
+	if _, err := os.Stat(target); err == nil {
+		return file, nil
+	}
+
+	data, err := c.download(ctx, u)
+	switch {
+	case errors.Is(err, errFileNotFound):
+		return "", nil
+	case err != nil:
+		return "", err
+	}
+	if err := WriteFileAtomic(target, data); err != nil {
+		return "", err
+	}
+	return file, nil
+}
+
+// download fetches the file at u. Missing files are reported as
+// errFileNotFound.
+func (c *Client) download(ctx context.Context, u *url.URL) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("failed to download %s: %w", u, errFileNotFound)
+	default:
+		return nil, fmt.Errorf("failed to download %s: %w: %d", u, ErrUnexpectedStatusCode, resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", u, err)
+	}
+	return data, nil
+}
+
+// fileURL returns the URL of the file uploaded to Telegraph that src refers
+// to. Relative sources are resolved against the upload URL.
+func (c *Client) fileURL(src string) (*url.URL, bool) {
+	base, err := url.Parse(c.uploadURL)
+	if err != nil {
+		return nil, false
+	}
+	ref, err := url.Parse(src)
+	if err != nil {
+		return nil, false
+	}
+	u := base.ResolveReference(ref)
+	if _, ok := telegraphHosts[u.Host]; !ok && u.Host != base.Host {
+		return nil, false
+	}
+	if !strings.HasPrefix(u.Path, "/file/") || path.Base(u.Path) == "file" {
+		return nil, false
+	}
+	return u, true
+}
+
+// mediaSources returns the distinct src attributes of the img and video
+// elements in content
+func mediaSources(content []Node) []string {
+	var sources []string
+	seen := make(map[string]bool)
+	RewriteURLs(content, func(tag, attr, value string) string {
+		if attr == "src" && (tag == "img" || tag == "video") && value != "" && !seen[value] {
+			seen[value] = true
+			sources = append(sources, value)
+		}
+		return value
+	})
+	return sources
+}
+
+// writeBackupIndex writes the index of the archive in dir
+func writeBackupIndex(dir string, index *BackupIndex) error {
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode backup index: %w", err)
+	}
+	return WriteFileAtomic(filepath.Join(dir, backupIndexFile), data)
+}
@@ -0,0 +1,146 @@
+package telegraph_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/smirnoffmg/telegraph"
+)
+
+// backupServer serves an account with two pages sharing an image. Requests
+// for the pages in failing are answered with an error.
+type backupServer struct {
+	mu      sync.Mutex
+	calls   []string
+	failing map[string]bool
+}
+
+func (s *backupServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, strings.TrimPrefix(r.URL.Path, "/"))
+
+	image := telegraph.NodeElement{Tag: "img", Attrs: map[string]string{"src": "/file/cat.png"}}
+	pages := map[string]telegraph.Page{
+		"First-10-19": {Path: "First-10-19", URL: "https://telegra.ph/First-10-19", Title: "First", Content: []telegraph.Node{
+			telegraph.NodeElement{Tag: "figure", Children: []telegraph.Node{image}},
+			telegraph.NodeElement{Tag: "img", Attrs: map[string]string{"src": "/file/gone.png"}},
+		}},
+		"Second-10-19": {Path: "Second-10-19", URL: "https://telegra.ph/Second-10-19", Title: "Second", Content: []telegraph.Node{
+			image,
+			telegraph.NodeElement{Tag: "img", Attrs: map[string]string{"src": "https://example.com/dog.png"}},
+		}},
+	}
+
+	var result interface{}
+	switch route := r.URL.Path; {
+	case route == "/file/cat.png":
+		_, _ = w.Write([]byte("cat"))
+		return
+	case strings.HasPrefix(route, "/file/"):
+		http.NotFound(w, r)
+		return
+	case route == "/getPageList":
+		result = telegraph.PageList{TotalCount: 2, Pages: []telegraph.Page{
+			{Path: "Second-10-19", Title: "Second"},
+			{Path: "First-10-19", Title: "First"},
+		}}
+	case strings.HasPrefix(route, "/getPage/"):
+		name := strings.TrimPrefix(route, "/getPage/")
+		if s.failing[name] {
+			_, _ = w.Write([]byte(`{"ok":false,"error":"FLOOD_WAIT_5"}`))
+			return
+		}
+		result = pages[name]
+	}
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "result": result}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// methods returns the recorded calls and forgets them
+func (s *backupServer) methods() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	calls := s.calls
+	s.calls = nil
+	return calls
+}
+
+func TestBackup(t *testing.T) {
+	api := &backupServer{failing: map[string]bool{"First-10-19": true}}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	client := telegraph.NewClient(server.Client())
+	client.SetBaseURL(server.URL + "/")
+	client.SetUploadURL(server.URL + "/upload")
+	dir := t.TempDir()
+
+	// The first run is interrupted by the failing page
+	if _, err := client.Backup(context.Background(), accessToken, dir); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+	if calls, want := api.methods(), []string{"getPageList", "getPage/Second-10-19", "file/cat.png", "getPage/First-10-19"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("Expected calls %v, got %v", want, calls)
+	}
+	index, err := telegraph.ReadBackup(dir)
+	if err != nil {
+		t.Fatalf("Expected partial backup, got %v", err)
+	}
+	if index.Complete || len(index.Pages) != 1 {
+		t.Errorf("Expected incomplete backup of 1 page, got %+v", index)
+	}
+
+	// Resuming only fetches the missing page
+	api.failing = nil
+	index, err = client.Backup(context.Background(), accessToken, dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if calls, want := api.methods(), []string{"getPageList", "getPage/First-10-19", "file/gone.png"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("Expected calls %v, got %v", want, calls)
+	}
+
+	if !index.Complete || index.Version != telegraph.BackupVersion {
+		t.Errorf("Expected complete backup of version %d, got %+v", telegraph.BackupVersion, index)
+	}
+	wantPages := []telegraph.BackupPage{
+		{Path: "Second-10-19", URL: "https://telegra.ph/Second-10-19", Title: "Second", File: "pages/Second-10-19.json"},
+		{Path: "First-10-19", URL: "https://telegra.ph/First-10-19", Title: "First", File: "pages/First-10-19.json"},
+	}
+	if !reflect.DeepEqual(index.Pages, wantPages) {
+		t.Errorf("Expected pages %+v, got %+v", wantPages, index.Pages)
+	}
+	if want := map[string]string{"/file/cat.png": "assets/cat.png"}; !reflect.DeepEqual(index.Assets, want) {
+		t.Errorf("Expected assets %v, got %v", want, index.Assets)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "assets", "cat.png")); err != nil || string(data) != "cat" {
+		t.Errorf("Expected downloaded image, got %q, %v", data, err)
+	}
+
+	var page telegraph.Page
+	data, err := os.ReadFile(filepath.Join(dir, "pages", "First-10-19.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &page); err != nil || page.Title != "First" || len(page.Content) != 2 {
+		t.Errorf("Expected saved page with content, got %+v, %v", page, err)
+	}
+
+	// A complete backup is taken again from scratch, apart from the files
+	if _, err := client.Backup(context.Background(), accessToken, dir); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if calls, want := api.methods(), []string{"getPageList", "getPage/Second-10-19", "getPage/First-10-19", "file/gone.png"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("Expected calls %v, got %v", want, calls)
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/smirnoffmg/telegraph"
+)
+
+// Fields shown by "account info" by default
+const defaultAccountFields = "short_name,author_name,author_url,page_count"
+
+func accountCreate(a *app, args []string) error {
+	var opts options
+	var shortName, authorName, authorURL string
+	var save, force bool
+	fs := a.flags("account create", &opts)
+	fs.StringVar(&shortName, "short-name", "", "account name shown to the user in Telegraph (required)")
+	fs.StringVar(&authorName, "author-name", "", "default author name of new pages")
+	fs.StringVar(&authorURL, "author-url", "", "default author link of new pages")
+	fs.BoolVar(&save, "save", false, "save the access token in the profile")
+	fs.BoolVar(&force, "force", false, "with --save, replace the token already saved in the profile")
+	if err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+	if shortName == "" {
+		fmt.Fprintln(a.stderr, "--short-name is required")
+		fs.Usage()
+		return errUsage
+	}
+	// Replacing the token of a profile would lose its account
+	if save && !force {
+		if err := a.checkProfileFree(&opts); err != nil {
+			return err
+		}
+	}
+
+	account, err := a.client(&opts).CreateAccountWith(a.ctx, telegraph.CreateAccountRequest{
+		ShortName:  shortName,
+		AuthorName: authorName,
+		AuthorURL:  authorURL,
+	})
+	if err != nil {
+		return err
+	}
+
+	if save {
+		if err := a.saveToken(&opts, account.AccessToken); err != nil {
+			fmt.Fprintf(a.stderr, "new access token: %s\n", account.AccessToken)
+			return err
+		}
+	}
+	return a.printAccount(&opts, account)
+}
+
+func accountInfo(a *app, args []string) error {
+	var opts options
+	var fields string
+	fs := a.flags("account info", &opts)
+	fs.StringVar(&fields, "fields", defaultAccountFields, "comma-separated fields to fetch, auth_url included")
+	if err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	token, _, err := a.token(&opts)
+	if err != nil {
+		return err
+	}
+
+	var req telegraph.GetAccountInfoRequest
+	req.AccessToken = token
+	for _, field := range strings.Split(fields, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			req.Fields = append(req.Fields, telegraph.AccountField(field))
+		}
+	}
+
+	account, err := a.client(&opts).GetAccountInfoWith(a.ctx, req)
+	if err != nil {
+		return err
+	}
+	return a.printAccount(&opts, account)
+}
+
+func accountEdit(a *app, args []string) error {
+	var opts options
+	var shortName, authorName, authorURL string
+	fs := a.flags("account edit", &opts)
+	fs.StringVar(&shortName, "short-name", "", "new account name")
+	fs.StringVar(&authorName, "author-name", "", "new default author name, empty to clear")
+	fs.StringVar(&authorURL, "author-url", "", "new default author link, empty to clear")
+	if err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	// Only the flags given are changed
+	var patch telegraph.AccountPatch
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "short-name":
+			patch.ShortName = telegraph.String(shortName)
+		case "author-name":
+			patch.AuthorName = telegraph.String(authorName)
+		case "author-url":
+			patch.AuthorURL = telegraph.String(authorURL)
+		}
+	})
+	if patch == (telegraph.AccountPatch{}) {
+		fmt.Fprintln(a.stderr, "nothing to change: give --short-name, --author-name or --author-url")
+		fs.Usage()
+		return errUsage
+	}
+
+	token, _, err := a.token(&opts)
+	if err != nil {
+		return err
+	}
+	account, err := a.client(&opts).EditAccountInfoWith(a.ctx, telegraph.EditAccountInfoRequest{
+		AccessToken:  token,
+		AccountPatch: patch,
+	})
+	if err != nil {
+		return err
+	}
+	return a.printAccount(&opts, account)
+}
+
+func accountRevoke(a *app, args []string) error {
+	var opts options
+	fs := a.flags("account revoke", &opts)
+	if err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	token, fromProfile, err := a.token(&opts)
+	if err != nil {
+		return err
+	}
+	account, err := a.client(&opts).RevokeAccessTokenWith(a.ctx, telegraph.RevokeAccessTokenRequest{AccessToken: token})
+	if err != nil {
+		return err
+	}
+
+	// The old token no longer works, so a profile must be updated right away
+	if fromProfile {
+		if err := a.saveToken(&opts, account.AccessToken); err != nil {
+			fmt.Fprintf(a.stderr, "new access token: %s\n", account.AccessToken)
+			return err
+		}
+	}
+	return a.printAccount(&opts, account)
+}
+
+// checkProfileFree fails if the selected profile already holds a token
+func (a *app) checkProfileFree(opts *options) error {
+	store, err := a.store(opts)
+	if err != nil {
+		return err
+	}
+	// Other errors show when the token is saved
+	if _, err := store.Load(a.profileName(opts)); err == nil {
+		return fmt.Errorf("profile %s already exists: give --force to replace its token or choose another --profile", a.profileName(opts))
+	}
+	return nil
+}
+
+// saveToken stores token in the selected profile
+func (a *app) saveToken(opts *options, token string) error {
+	store, err := a.store(opts)
+	if err != nil {
+		return err
+	}
+	if err := store.Save(a.profileName(opts), token); err != nil {
+		return fmt.Errorf("failed to save access token: %w", err)
+	}
+	return nil
+}
+
+// printAccount prints the non-empty fields of account
+func (a *app) printAccount(opts *options, account *telegraph.Account) error {
+	if opts.json {
+		return a.printJSON(account)
+	}
+
+	rows := [][2]string{
+		{"Short name", account.ShortName},
+		{"Author name", account.AuthorName},
+		{"Author URL", account.AuthorURL},
+		{"Auth URL", account.AuthURL},
+		{"Access token", account.AccessToken},
+	}
+	if account.PageCount > 0 {
+		rows = append(rows, [2]string{"Pages", strconv.Itoa(account.PageCount)})
+	}
+
+	tw := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+	for _, row := range rows {
+		if row[1] != "" {
+			fmt.Fprintf(tw, "%s:\t%s\n", row[0], row[1])
+		}
+	}
+	return tw.Flush()
+}
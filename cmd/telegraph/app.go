@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/smirnoffmg/telegraph"
+)
+
+// Timeout of API requests
+const requestTimeout = 30 * time.Second
+
+// defaultProfile is used when no profile is given
+const defaultProfile = "default"
+
+// Environment variables read by the tool
+const (
+	envToken     = "TELEGRAPH_TOKEN"
+	envProfile   = "TELEGRAPH_PROFILE"
+	envProfiles  = "TELEGRAPH_PROFILES"
+	envAPIURL    = "TELEGRAPH_API_URL"
+	envUploadURL = "TELEGRAPH_UPLOAD_URL"
+)
+
+// app holds the environment of the tool
+type app struct {
+	ctx        context.Context // canceled when the tool is interrupted
+	stdout     io.Writer
+	stderr     io.Writer
+	getenv     func(string) string
+	httpClient *http.Client
+}
+
+// options are the flags shared by all commands
+type options struct {
+	token     string
+	profile   string
+	profiles  string
+	apiURL    string
+	uploadURL string
+	json      bool
+}
+
+func newApp(stdout, stderr io.Writer, getenv func(string) string) *app {
+	return &app{
+		ctx:        context.Background(),
+		stdout:     stdout,
+		stderr:     stderr,
+		getenv:     getenv,
+		httpClient: &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// flags creates the flag set of a command with the shared flags registered
+func (a *app) flags(name string, opts *options) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.StringVar(&opts.token, "token", "", "access token (default $"+envToken+" or the profile's token)")
+	fs.StringVar(&opts.profile, "profile", "", "profile to use (default $"+envProfile+" or \""+defaultProfile+"\")")
+	fs.StringVar(&opts.profiles, "profiles", "", "profiles file (default $"+envProfiles+" or telegraph/profiles.json in the user config directory)")
+	fs.StringVar(&opts.apiURL, "api-url", "", "base URL of the API (default $"+envAPIURL+")")
+	fs.StringVar(&opts.uploadURL, "upload-url", "", "URL files are uploaded to (default $"+envUploadURL+")")
+	fs.BoolVar(&opts.json, "json", false, "print JSON instead of a table")
+	return fs
+}
+
+// client returns an API client configured by opts
+func (a *app) client(opts *options) *telegraph.Client {
+	client := telegraph.NewClient(a.httpClient)
+	if url := firstOf(opts.apiURL, a.getenv(envAPIURL)); url != "" {
+		client.SetBaseURL(url)
+	}
+	if url := firstOf(opts.uploadURL, a.getenv(envUploadURL)); url != "" {
+		client.SetUploadURL(url)
+	}
+	return client
+}
+
+// profileName returns the name of the profile selected by opts
+func (a *app) profileName(opts *options) string {
+	return firstOf(opts.profile, a.getenv(envProfile), defaultProfile)
+}
+
+// store returns the token store holding the profiles
+func (a *app) store(opts *options) (*telegraph.FileTokenStore, error) {
+	path := firstOf(opts.profiles, a.getenv(envProfiles))
+	if path == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to locate profiles: %w", err)
+		}
+		path = filepath.Join(dir, "telegraph", "profiles.json")
+	}
+	return telegraph.NewFileTokenStore(path), nil
+}
+
+// token returns the access token selected by opts and whether it was taken
+// from the profile
+func (a *app) token(opts *options) (string, bool, error) {
+	if token := firstOf(opts.token, a.getenv(envToken)); token != "" {
+		return token, false, nil
+	}
+
+	store, err := a.store(opts)
+	if err != nil {
+		return "", false, err
+	}
+	token, err := store.Load(a.profileName(opts))
+	if errors.Is(err, telegraph.ErrTokenNotFound) {
+		return "", false, fmt.Errorf("no access token: use --token, $%s or create an account with --save (%w)", envToken, err)
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return token, true, nil
+}
+
+// printJSON writes v to stdout as indented JSON
+func (a *app) printJSON(v interface{}) error {
+	enc := json.NewEncoder(a.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// firstOf returns the first non-empty value
+func firstOf(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,35 @@
+package main
+
+import "fmt"
+
+func backup(a *app, args []string) error {
+	var opts options
+	fs := a.flags("backup", &opts)
+	fs.Usage = func() {
+		fmt.Fprintln(a.stderr, "Usage: telegraph backup [flags] DIR")
+		fs.PrintDefaults()
+	}
+	if err := a.parse(fs, args, 1); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+	dir := fs.Arg(0)
+
+	token, _, err := a.token(&opts)
+	if err != nil {
+		return err
+	}
+	index, err := a.client(&opts).Backup(a.ctx, token, dir)
+	if err != nil {
+		return fmt.Errorf("%w; run the command again to resume", err)
+	}
+
+	if opts.json {
+		return a.printJSON(index)
+	}
+	fmt.Fprintf(a.stdout, "Backed up %d pages and %d files to %s\n", len(index.Pages), len(index.Assets), dir)
+	return nil
+}
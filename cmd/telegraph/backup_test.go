@@ -0,0 +1,42 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBackup(t *testing.T) {
+	server, rec := apiServer(t, map[string]string{
+		"getPageList": `{"total_count":2,"pages":[{"path":"New-02-02","title":"New"},{"path":"Old-01-01","title":"Old"}]}`,
+		"getPage":     `{"path":"Old-01-01","url":"https://telegra.ph/Old-01-01","title":"Old","content":["Hi"]}`,
+	})
+	a, stdout, stderr := testApp(t, server, map[string]string{envToken: "token"})
+	dir := filepath.Join(t.TempDir(), "backup")
+
+	if code := a.run([]string{"backup", dir}); code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, stderr)
+	}
+	if want := "Backed up 2 pages and 0 files to " + dir + "\n"; stdout.String() != want {
+		t.Errorf("Expected output %q, got %q", want, stdout)
+	}
+	if calls, want := rec.methods(), []string{"getPageList", "getPage", "getPage"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("Expected calls %v, got %v", want, calls)
+	}
+	for _, name := range []string{"index.json", "pages/New-02-02.json", "pages/Old-01-01.json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("Expected %s in the backup: %v", name, err)
+		}
+	}
+
+	stderr.Reset()
+	server.Close()
+	if code := a.run([]string{"backup", dir}); code != exitError {
+		t.Fatalf("Expected exit code %d, got %d", exitError, code)
+	}
+	if !strings.Contains(stderr.String(), "run the command again to resume") {
+		t.Errorf("Expected resume hint, got %q", stderr)
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/smirnoffmg/telegraph"
+)
+
+// errUnsupportedFile is returned for files that are neither Markdown nor HTML
+var errUnsupportedFile = errors.New("unsupported file type, expected .md or .html")
+
+// document is a page read from a Markdown or HTML file
+type document struct {
+	path       string
+	title      string
+	authorName string
+	authorURL  string
+	content    []telegraph.Node
+}
+
+// isSource reports whether path has the extension of a supported file
+func isSource(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown", ".html", ".htm":
+		return true
+	}
+	return false
+}
+
+// loadDocument reads and converts the file at path. The title and author
+// are taken from the front matter; Markdown documents without a title use
+// their leading level 1 heading, and all others the file name.
+func loadDocument(path string) (*document, error) {
+	if !isSource(path) {
+		return nil, fmt.Errorf("%s: %w", path, errUnsupportedFile)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	meta, body := parseFrontMatter(string(data))
+	doc := &document{
+		path:       path,
+		title:      meta["title"],
+		authorName: firstOf(meta["author_name"], meta["author"]),
+		authorURL:  meta["author_url"],
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".md" || ext == ".markdown" {
+		if doc.title == "" {
+			doc.title, body = leadingHeading(body)
+		}
+		doc.content, err = telegraph.MarkdownToContent(body)
+	} else {
+		doc.content, err = telegraph.HTMLToContent(body)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if doc.title == "" {
+		doc.title = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return doc, nil
+}
+
+// parseFrontMatter splits a YAML front matter block delimited by "---"
+// lines from the body of a document. Only flat "key: value" pairs are
+// supported; keys are lowercased and values unquoted.
+func parseFrontMatter(src string) (map[string]string, string) {
+	meta := make(map[string]string)
+	src = strings.TrimPrefix(src, "\ufeff")
+	lines := strings.SplitAfter(src, "\n")
+	if strings.TrimRight(lines[0], "\r\n") != "---" {
+		return meta, src
+	}
+
+	consumed := len(lines[0])
+	for _, line := range lines[1:] {
+		consumed += len(line)
+		line = strings.TrimRight(line, "\r\n")
+		if strings.TrimRight(line, " \t") == "---" {
+			return meta, src[consumed:]
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		meta[strings.ToLower(strings.TrimSpace(key))] = unquote(strings.TrimSpace(value))
+	}
+
+	// Without a closing delimiter there is no front matter
+	return make(map[string]string), src
+}
+
+// unquote removes the quotes around a front matter value. Escapes are only
+// interpreted in double-quoted values.
+func unquote(value string) string {
+	if len(value) < 2 || (value[0] != '"' && value[0] != '\'') || value[len(value)-1] != value[0] {
+		return value
+	}
+	if value[0] == '"' {
+		if s, err := strconv.Unquote(value); err == nil {
+			return s
+		}
+	}
+	return value[1 : len(value)-1]
+}
+
+// closingHashes matches the optional closing sequence of an ATX heading
+var closingHashes = regexp.MustCompile(`\s+#+\s*$`)
+
+// leadingHeading removes a level 1 ATX heading starting the Markdown body
+// and returns its text
+func leadingHeading(body string) (string, string) {
+	rest := strings.TrimLeft(body, "\r\n")
+	line, after, _ := strings.Cut(rest, "\n")
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "# ") {
+		return "", body
+	}
+	return strings.TrimSpace(closingHashes.ReplaceAllString(line[2:], "")), after
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/smirnoffmg/telegraph"
+)
+
+func export(a *app, args []string) error {
+	var opts options
+	var site telegraph.SiteOptions
+	fs := a.flags("export", &opts)
+	fs.StringVar(&site.Title, "title", "", "title of the site and its feed (default \"Telegraph\")")
+	fs.StringVar(&site.BaseURL, "base-url", "", "URL the site will be served from, used by the feed")
+	fs.Usage = func() {
+		fmt.Fprintln(a.stderr, "Usage: telegraph export [flags] DIR")
+		fs.PrintDefaults()
+	}
+	if err := a.parse(fs, args, 1); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+	dir := fs.Arg(0)
+
+	token, _, err := a.token(&opts)
+	if err != nil {
+		return err
+	}
+	pages, err := a.client(&opts).ExportSite(a.ctx, token, dir, site)
+	if err != nil {
+		return err
+	}
+
+	if opts.json {
+		return a.printJSON(pages)
+	}
+	fmt.Fprintf(a.stdout, "Exported %d pages to %s\n", len(pages), dir)
+	return nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExport(t *testing.T) {
+	server, _ := apiServer(t, map[string]string{
+		"getPageList": `{"total_count":1,"pages":[{"path":"Hello-01-02","title":"Hello"}]}`,
+		"getPage":     `{"path":"Hello-01-02","url":"https://telegra.ph/Hello-01-02","title":"Hello","content":["Hi"]}`,
+	})
+	a, stdout, stderr := testApp(t, server, map[string]string{envToken: "token"})
+	dir := filepath.Join(t.TempDir(), "site")
+
+	if code := a.run([]string{"export", "--title", "Blog", "--base-url", "https://blog.example.com", dir}); code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, stderr)
+	}
+	if want := "Exported 1 pages to " + dir + "\n"; stdout.String() != want {
+		t.Errorf("Expected output %q, got %q", want, stdout)
+	}
+	for _, name := range []string{"Hello-01-02.html", "index.html", "feed.xml"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("Expected %s in the site: %v", name, err)
+		}
+	}
+	feed, err := os.ReadFile(filepath.Join(dir, "feed.xml"))
+	if err != nil || !strings.Contains(string(feed), "https://blog.example.com/Hello-01-02.html") {
+		t.Errorf("Expected the feed to link to the base URL, got %s (%v)", feed, err)
+	}
+
+	if code := a.run([]string{"export"}); code != exitUsage {
+		t.Errorf("Expected exit code %d without a directory, got %d", exitUsage, code)
+	}
+}
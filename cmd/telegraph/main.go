@@ -0,0 +1,146 @@
+// Command telegraph manages Telegraph accounts and pages from the command line.
+//
+// Usage:
+//
+//	telegraph <command> [subcommand] [flags]
+//
+// Access tokens are taken from the --token flag, the TELEGRAPH_TOKEN
+// environment variable or the profile named by --profile (TELEGRAPH_PROFILE,
+// "default" when unset) in the profiles file, in that order.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+)
+
+// Exit codes
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+// command is a subcommand, possibly with subcommands of its own
+type command struct {
+	summary  string
+	run      func(app *app, args []string) error
+	commands map[string]*command
+}
+
+// commands lists the top-level commands of the tool
+var commands = map[string]*command{
+	"account": {
+		summary: "manage the account",
+		commands: map[string]*command{
+			"create": {summary: "create an account", run: accountCreate},
+			"info":   {summary: "show account information", run: accountInfo},
+			"edit":   {summary: "change account information", run: accountEdit},
+			"revoke": {summary: "revoke the access token and get a new one", run: accountRevoke},
+		},
+	},
+	"publish": {summary: "publish a Markdown or HTML file", run: publish},
+	"preview": {summary: "preview Markdown or HTML files in the browser", run: preview},
+	"backup":  {summary: "save all pages and their images to a directory", run: backup},
+	"export":  {summary: "export all pages as a static website", run: export},
+	"pages": {
+		summary: "list pages of the account",
+		commands: map[string]*command{
+			"list": {summary: "list all pages", run: pagesList},
+		},
+	},
+	"page": {
+		summary: "show a page",
+		commands: map[string]*command{
+			"get": {summary: "print a page as HTML, Markdown or JSON", run: pageGet},
+		},
+	},
+	"views":   {summary: "show views of a page", run: views},
+	"restore": {summary: "republish the pages of a backup into the account", run: restore},
+	"migrate": {summary: "copy all pages of another account into the account", run: migrate},
+	"sync":    {summary: "publish a directory of linked documents", run: syncDir},
+	"watch":   {summary: "republish a file to its page whenever it is saved", run: watchFile},
+}
+
+// errUsage reports a command line error after its usage has been printed
+var errUsage = errors.New("usage error")
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	a := newApp(os.Stdout, os.Stderr, os.Getenv)
+	a.ctx = ctx
+	code := a.run(os.Args[1:])
+	stop()
+	os.Exit(code)
+}
+
+// run executes the command given by args and returns the exit code
+func (a *app) run(args []string) int {
+	cmd, name := &command{commands: commands}, "telegraph"
+	for cmd.run == nil {
+		if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+			a.usage(name, cmd)
+			if len(args) == 0 {
+				return exitUsage
+			}
+			return exitOK
+		}
+		next, ok := cmd.commands[args[0]]
+		if !ok {
+			fmt.Fprintf(a.stderr, "%s: unknown command %q\n", name, args[0])
+			a.usage(name, cmd)
+			return exitUsage
+		}
+		cmd, name, args = next, name+" "+args[0], args[1:]
+	}
+
+	err := cmd.run(a, args)
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.Is(err, errUsage):
+		return exitUsage
+	}
+	fmt.Fprintf(a.stderr, "%s: %v\n", name, err)
+	return exitError
+}
+
+// usage prints the subcommands of cmd
+func (a *app) usage(name string, cmd *command) {
+	fmt.Fprintf(a.stderr, "Usage: %s <command> [flags]\n\nCommands:\n", name)
+	names := make([]string, 0, len(cmd.commands))
+	for sub := range cmd.commands {
+		names = append(names, sub)
+	}
+	sort.Strings(names)
+	for _, sub := range names {
+		fmt.Fprintf(a.stderr, "  %-10s %s\n", sub, cmd.commands[sub].summary)
+	}
+}
+
+// parse parses the flags of a leaf command and rejects extra arguments
+// beyond maxArgs
+func (a *app) parse(fs *flag.FlagSet, args []string, maxArgs int) error {
+	fs.SetOutput(a.stderr)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+	if fs.NArg() > maxArgs {
+		fmt.Fprintf(a.stderr, "unexpected arguments: %s\n", strings.Join(fs.Args()[maxArgs:], " "))
+		fs.Usage()
+		return errUsage
+	}
+	return nil
+}
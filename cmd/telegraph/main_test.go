@@ -0,0 +1,278 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/smirnoffmg/telegraph"
+)
+
+// uploadSrc is the src of files uploaded to an apiServer
+const uploadSrc = "/file/6a5b15e7eb4d7329ca7af.png"
+
+// apiRecorder records the requests received by an apiServer
+type apiRecorder struct {
+	mu     sync.Mutex
+	calls  []string                          // API methods in the order they were called
+	bodies map[string]map[string]interface{} // last decoded request body of each method
+
+	// results computes the results of methods from their request bodies,
+	// taking precedence over the fixed results
+	results map[string]func(body map[string]interface{}) string
+}
+
+// methods returns the recorded calls and forgets them
+func (r *apiRecorder) methods() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	calls := r.calls
+	r.calls = nil
+	return calls
+}
+
+// body returns the last request body of method
+func (r *apiRecorder) body(method string) map[string]interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.bodies[method]
+}
+
+// apiServer answers API methods with the given results and records the
+// requests. Uploads to /upload are answered with uploadSrc.
+func apiServer(t *testing.T, results map[string]string) (*httptest.Server, *apiRecorder) {
+	t.Helper()
+	rec := &apiRecorder{bodies: make(map[string]map[string]interface{})}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")[0]
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+		rec.calls = append(rec.calls, method)
+
+		if method == "upload" {
+			_, _ = w.Write([]byte(`[{"src":"` + uploadSrc + `"}]`))
+			return
+		}
+
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Failed to decode %s request: %v", method, err)
+		}
+		rec.bodies[method] = body
+
+		result, ok := results[method]
+		if respond, dynamic := rec.results[method]; dynamic {
+			result, ok = respond(body), true
+		}
+		if !ok {
+			_, _ = w.Write([]byte(`{"ok":false,"error":"METHOD_NOT_FOUND"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"result":` + result + `}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, rec
+}
+
+// testApp returns an app talking to server with profiles kept in a
+// temporary directory, and its output
+func testApp(t *testing.T, server *httptest.Server, env map[string]string) (*app, *bytes.Buffer, *bytes.Buffer) {
+	t.Helper()
+	vars := map[string]string{
+		envAPIURL:    server.URL + "/",
+		envUploadURL: server.URL + "/upload",
+		envProfiles:  filepath.Join(t.TempDir(), "profiles.json"),
+	}
+	for key, value := range env {
+		vars[key] = value
+	}
+	var stdout, stderr bytes.Buffer
+	a := newApp(&stdout, &stderr, func(key string) string { return vars[key] })
+	a.httpClient = server.Client()
+	return a, &stdout, &stderr
+}
+
+func TestAccountCommands(t *testing.T) {
+	server, requests := apiServer(t, map[string]string{
+		"createAccount":     `{"short_name":"Sandbox","author_name":"Anonymous","access_token":"token-1"}`,
+		"getAccountInfo":    `{"short_name":"Sandbox","author_name":"Anonymous","page_count":3}`,
+		"editAccountInfo":   `{"short_name":"Sandbox","author_name":"Writer"}`,
+		"revokeAccessToken": `{"access_token":"token-2","auth_url":"https://edit.telegra.ph/auth/x"}`,
+	})
+	a, stdout, stderr := testApp(t, server, nil)
+
+	steps := []struct {
+		name       string
+		args       []string
+		wantOutput []string
+		check      func(t *testing.T)
+	}{
+		{
+			name:       "Create and save",
+			args:       []string{"account", "create", "--short-name", "Sandbox", "--author-name", "Anonymous", "--save"},
+			wantOutput: []string{"Short name:", "Sandbox", "Access token:", "token-1"},
+		},
+		{
+			name:       "Info uses the saved profile",
+			args:       []string{"account", "info"},
+			wantOutput: []string{"Pages:", "3"},
+			check: func(t *testing.T) {
+				if requests.body("getAccountInfo")["access_token"] != "token-1" {
+					t.Errorf("Expected the profile's token, got %v", requests.body("getAccountInfo"))
+				}
+			},
+		},
+		{
+			name:       "Edit sends given fields only",
+			args:       []string{"account", "edit", "--author-name", "Writer", "--json"},
+			wantOutput: []string{`"author_name": "Writer"`},
+			check: func(t *testing.T) {
+				if _, ok := requests.body("editAccountInfo")["short_name"]; ok {
+					t.Errorf("Expected no short_name, got %v", requests.body("editAccountInfo"))
+				}
+			},
+		},
+		{
+			name:       "Revoke updates the profile",
+			args:       []string{"account", "revoke"},
+			wantOutput: []string{"token-2"},
+			check: func(t *testing.T) {
+				store, err := a.store(&options{})
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if token, err := store.Load(defaultProfile); err != nil || token != "token-2" {
+					t.Errorf("Expected saved token 'token-2', got '%s' (%v)", token, err)
+				}
+			},
+		},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			stdout.Reset()
+			stderr.Reset()
+			if code := a.run(step.args); code != exitOK {
+				t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, stderr)
+			}
+			for _, want := range step.wantOutput {
+				if !strings.Contains(stdout.String(), want) {
+					t.Errorf("Expected output to contain '%s', got:\n%s", want, stdout)
+				}
+			}
+			if step.check != nil {
+				step.check(t)
+			}
+		})
+	}
+}
+
+func TestAccountCreateSave(t *testing.T) {
+	server, requests := apiServer(t, map[string]string{
+		"createAccount": `{"short_name":"Sandbox","access_token":"token-1"}`,
+	})
+	create := []string{"account", "create", "--short-name", "Sandbox", "--save"}
+
+	// An existing profile is kept unless --force is given
+	a, _, stderr := testApp(t, server, nil)
+	store, err := a.store(&options{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := store.Save(defaultProfile, "old-token"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if code := a.run(create); code != exitError || !strings.Contains(stderr.String(), "--force") {
+		t.Errorf("Expected exit code %d with a hint to --force, got %d: %s", exitError, code, stderr)
+	}
+	if calls := requests.methods(); len(calls) != 0 {
+		t.Errorf("Expected no account to be created, got %v", calls)
+	}
+	if code := a.run(append(create, "--force")); code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, stderr)
+	}
+	if token, err := store.Load(defaultProfile); err != nil || token != "token-1" {
+		t.Errorf("Expected saved token 'token-1', got '%s' (%v)", token, err)
+	}
+
+	// The token is shown when it cannot be saved
+	blocker := filepath.Join(t.TempDir(), "file")
+	writeFile(t, blocker, "")
+	a, _, stderr = testApp(t, server, map[string]string{envProfiles: filepath.Join(blocker, "profiles.json")})
+	if code := a.run(create); code != exitError {
+		t.Errorf("Expected exit code %d, got %d", exitError, code)
+	}
+	if !strings.Contains(stderr.String(), "new access token: token-1") {
+		t.Errorf("Expected the new token on stderr, got %q", stderr)
+	}
+}
+
+func TestTokenSources(t *testing.T) {
+	server, requests := apiServer(t, map[string]string{"getAccountInfo": `{"short_name":"Sandbox"}`})
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		args []string
+		want string
+	}{
+		{"Flag", map[string]string{envToken: "env-token"}, []string{"--token", "flag-token"}, "flag-token"},
+		{"Environment", map[string]string{envToken: "env-token"}, nil, "env-token"},
+		{"Profile", map[string]string{envProfile: "work"}, nil, "work-token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, _, stderr := testApp(t, server, tt.env)
+			store, err := a.store(&options{})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if err := store.Save("work", "work-token"); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if code := a.run(append([]string{"account", "info"}, tt.args...)); code != exitOK {
+				t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, stderr)
+			}
+			if got := requests.body("getAccountInfo")["access_token"]; got != tt.want {
+				t.Errorf("Expected token '%s', got '%v'", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	server, _ := apiServer(t, nil)
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		wantErr  string
+	}{
+		{"No command", nil, exitUsage, "Commands:"},
+		{"Unknown command", []string{"frobnicate"}, exitUsage, `unknown command "frobnicate"`},
+		{"Missing short name", []string{"account", "create"}, exitUsage, "--short-name is required"},
+		{"Nothing to edit", []string{"account", "edit"}, exitUsage, "nothing to change"},
+		{"No token", []string{"account", "info"}, exitError, telegraph.ErrTokenNotFound.Error()},
+		{"API error", []string{"account", "info", "--token", "x"}, exitError, "METHOD_NOT_FOUND"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, _, stderr := testApp(t, server, nil)
+			if code := a.run(tt.args); code != tt.wantCode {
+				t.Errorf("Expected exit code %d, got %d", tt.wantCode, code)
+			}
+			if !strings.Contains(stderr.String(), tt.wantErr) {
+				t.Errorf("Expected error output to contain '%s', got:\n%s", tt.wantErr, stderr)
+			}
+		})
+	}
+}
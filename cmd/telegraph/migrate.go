@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/smirnoffmg/telegraph"
+)
+
+func restore(a *app, args []string) error {
+	var opts options
+	var mapPath string
+	fs := a.flags("restore", &opts)
+	fs.StringVar(&mapPath, "map", "", "file to keep the map of old to new paths in, resuming from it if it exists")
+	fs.Usage = func() {
+		fmt.Fprintln(a.stderr, "Usage: telegraph restore [flags] DIR")
+		fs.PrintDefaults()
+	}
+	if err := a.parse(fs, args, 1); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+
+	token, _, err := a.token(&opts)
+	if err != nil {
+		return err
+	}
+	done, copied, err := pathRecorder(mapPath)
+	if err != nil {
+		return err
+	}
+	paths, err := a.client(&opts).Restore(a.ctx, token, fs.Arg(0), done, copied)
+	return a.finishMigration(&opts, mapPath, paths, err)
+}
+
+func migrate(a *app, args []string) error {
+	var opts, from options
+	var mapPath string
+	fs := a.flags("migrate", &opts)
+	fs.StringVar(&from.token, "from-token", "", "access token of the account to copy pages from")
+	fs.StringVar(&from.profile, "from-profile", "", "profile of the account to copy pages from")
+	fs.StringVar(&mapPath, "map", "", "file to keep the map of old to new paths in, resuming from it if it exists")
+	fs.Usage = func() {
+		fmt.Fprintln(a.stderr, "Usage: telegraph migrate [flags] --from-token TOKEN|--from-profile NAME")
+		fmt.Fprintln(a.stderr, "Copies all pages of an account into the account selected by --token or --profile.")
+		fs.PrintDefaults()
+	}
+	if err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+	if (from.token == "") == (from.profile == "") {
+		fmt.Fprintln(a.stderr, "give either --from-token or --from-profile")
+		fs.Usage()
+		return errUsage
+	}
+
+	token, _, err := a.token(&opts)
+	if err != nil {
+		return err
+	}
+	from.profiles = opts.profiles
+	fromToken := from.token
+	if fromToken == "" {
+		if fromToken, err = a.profileToken(&from); err != nil {
+			return err
+		}
+	}
+	if fromToken == token {
+		return errors.New("the accounts to copy pages from and to are the same")
+	}
+
+	done, copied, err := pathRecorder(mapPath)
+	if err != nil {
+		return err
+	}
+	paths, err := a.client(&opts).Migrate(a.ctx, fromToken, token, done, copied)
+	return a.finishMigration(&opts, mapPath, paths, err)
+}
+
+// profileToken returns the access token saved in the profile named by opts
+func (a *app) profileToken(opts *options) (string, error) {
+	store, err := a.store(opts)
+	if err != nil {
+		return "", err
+	}
+	token, err := store.Load(opts.profile)
+	if err != nil {
+		return "", fmt.Errorf("failed to load profile %s: %w", opts.profile, err)
+	}
+	return token, nil
+}
+
+// finishMigration prints the paths of the pages copied by a restore or
+// migration that ended with err
+func (a *app) finishMigration(opts *options, mapPath string, paths map[string]string, err error) error {
+	// Pages copied before a failure are listed too, so they can be found
+	if opts.json {
+		if printErr := a.printJSON(paths); printErr != nil {
+			return printErr
+		}
+	} else {
+		old := make([]string, 0, len(paths))
+		for path := range paths {
+			old = append(old, path)
+		}
+		sort.Strings(old)
+		tw := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "OLD\tNEW")
+		for _, path := range old {
+			fmt.Fprintf(tw, "%s\t%s\n", path, paths[path])
+		}
+		if flushErr := tw.Flush(); flushErr != nil {
+			return flushErr
+		}
+	}
+
+	if err != nil && mapPath != "" {
+		return fmt.Errorf("%w; run the command again with the same --map to resume", err)
+	}
+	return err
+}
+
+// pathRecorder returns the map of old to new paths saved at mapPath, if
+// any, and a callback adding each page to it as soon as it was copied, so
+// that an interrupted run can be resumed without creating duplicates
+func pathRecorder(mapPath string) (map[string]string, func(from, to string) error, error) {
+	done, err := readPathMap(mapPath)
+	if err != nil || mapPath == "" {
+		return done, nil, err
+	}
+	paths := make(map[string]string, len(done))
+	for from, to := range done {
+		paths[from] = to
+	}
+	return done, func(from, to string) error {
+		paths[from] = to
+		return writePathMap(mapPath, paths)
+	}, nil
+}
+
+// readPathMap reads the map of old to new paths at path, which may not
+// exist yet
+func readPathMap(path string) (map[string]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read path map: %w", err)
+	}
+	var paths map[string]string
+	if err := json.Unmarshal(data, &paths); err != nil {
+		return nil, fmt.Errorf("failed to decode path map %s: %w", path, err)
+	}
+	return paths, nil
+}
+
+// writePathMap writes the map of old to new paths to path
+func writePathMap(path string, paths map[string]string) error {
+	data, err := json.MarshalIndent(paths, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := telegraph.WriteFileAtomic(path, append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write path map: %w", err)
+	}
+	return nil
+}
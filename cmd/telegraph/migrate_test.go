@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRestoreAndMigrate(t *testing.T) {
+	server, rec := apiServer(t, map[string]string{
+		"getPageList": `{"total_count":2,"pages":[{"path":"New-02-02","title":"New"},{"path":"Old-01-01","title":"Old"}]}`,
+		"getPage":     `{"path":"Old-01-01","url":"https://telegra.ph/Old-01-01","title":"Old","content":["Hi"]}`,
+	})
+	created := 0
+	rec.results = map[string]func(map[string]interface{}) string{
+		"createPage": func(map[string]interface{}) string {
+			created++
+			return map[int]string{1: `{"path":"Old-10-19"}`, 2: `{"path":"New-10-19"}`, 3: `{"path":"Old-10-19-2"}`, 4: `{"path":"New-10-19-2"}`}[created]
+		},
+	}
+	a, stdout, stderr := testApp(t, server, map[string]string{envToken: "token"})
+
+	dir := t.TempDir()
+	backupDir := filepath.Join(dir, "backup")
+	if code := a.run([]string{"backup", backupDir}); code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, stderr)
+	}
+	rec.methods()
+
+	mapPath := filepath.Join(dir, "paths.json")
+	wantPaths := map[string]string{"Old-01-01": "Old-10-19", "New-02-02": "New-10-19"}
+	steps := []struct {
+		name      string
+		args      []string
+		wantCode  int
+		wantCalls []string
+		wantOut   string
+	}{
+		{
+			name:      "Restore creates pages oldest first",
+			args:      []string{"restore", "--map", mapPath, backupDir},
+			wantCalls: []string{"createPage", "createPage"},
+			wantOut:   "OLD        NEW\nNew-02-02  New-10-19\nOld-01-01  Old-10-19\n",
+		},
+		{
+			name:    "Restore again resumes from the map",
+			args:    []string{"restore", "--map", mapPath, backupDir},
+			wantOut: "OLD        NEW\nNew-02-02  New-10-19\nOld-01-01  Old-10-19\n",
+		},
+		{
+			name:     "Migrate needs a source account",
+			args:     []string{"migrate"},
+			wantCode: exitUsage,
+		},
+		{
+			name:     "Migrate to the same account",
+			args:     []string{"migrate", "--from-token", "token"},
+			wantCode: exitError,
+		},
+		{
+			name:      "Migrate from another account",
+			args:      []string{"migrate", "--from-token", "other", "--json"},
+			wantCalls: []string{"getPageList", "getPage", "getPage", "createPage", "createPage"},
+			wantOut:   "{\n  \"New-02-02\": \"New-10-19-2\",\n  \"Old-01-01\": \"Old-10-19-2\"\n}\n",
+		},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			stdout.Reset()
+			stderr.Reset()
+			if code := a.run(step.args); code != step.wantCode {
+				t.Fatalf("Expected exit code %d, got %d: %s", step.wantCode, code, stderr)
+			}
+			if calls := rec.methods(); !reflect.DeepEqual(calls, step.wantCalls) {
+				t.Errorf("Expected calls %v, got %v", step.wantCalls, calls)
+			}
+			if stdout.String() != step.wantOut {
+				t.Errorf("Expected output %q, got %q", step.wantOut, stdout)
+			}
+		})
+	}
+
+	data, err := os.ReadFile(mapPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths map[string]string
+	if err := json.Unmarshal(data, &paths); err != nil || !reflect.DeepEqual(paths, wantPaths) {
+		t.Errorf("Expected path map %v, got %s", wantPaths, data)
+	}
+	if !strings.Contains(rec.body("createPage")["content"].([]interface{})[0].(string), "Hi") {
+		t.Errorf("Expected page content to be copied, got %v", rec.body("createPage"))
+	}
+}
+
+func TestMigrateSavesEachPath(t *testing.T) {
+	server, rec := apiServer(t, map[string]string{
+		"getPageList": `{"total_count":2,"pages":[{"path":"New-02-02","title":"New"},{"path":"Old-01-01","title":"Old"}]}`,
+		"getPage":     `{"path":"Old-01-01","title":"Old","content":["Hi"]}`,
+	})
+	created := 0
+	rec.results = map[string]func(map[string]interface{}) string{
+		"createPage": func(map[string]interface{}) string {
+			created++
+			if created > 1 {
+				return `"broken"`
+			}
+			return `{"path":"Old-10-19"}`
+		},
+	}
+	a, _, stderr := testApp(t, server, map[string]string{envToken: "token"})
+
+	// The page copied before the failure is in the map
+	mapPath := filepath.Join(t.TempDir(), "paths.json")
+	if code := a.run([]string{"migrate", "--from-token", "other", "--map", mapPath}); code != exitError {
+		t.Fatalf("Expected exit code %d, got %d: %s", exitError, code, stderr)
+	}
+	data, err := os.ReadFile(mapPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths map[string]string
+	if err := json.Unmarshal(data, &paths); err != nil || !reflect.DeepEqual(paths, map[string]string{"Old-01-01": "Old-10-19"}) {
+		t.Errorf("Expected the copied page in the path map, got %s", data)
+	}
+}
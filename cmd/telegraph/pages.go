@@ -0,0 +1,290 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/smirnoffmg/telegraph"
+)
+
+// dateLayout is the layout of dates given on the command line
+const dateLayout = "2006-01-02"
+
+// maxViewRequests limits the number of getViews requests of one command
+const maxViewRequests = 31 * 24
+
+// sparks are the bars of a sparkline from lowest to highest
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+func pagesList(a *app, args []string) error {
+	var opts options
+	var sortBy string
+	var limit int
+	fs := a.flags("pages list", &opts)
+	fs.StringVar(&sortBy, "sort", "", "sort by views or title instead of newest first")
+	fs.IntVar(&limit, "limit", 0, "show at most this many pages, 0 for all")
+	if err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	var less func(p, q telegraph.Page) bool
+	switch sortBy {
+	case "":
+	case "views":
+		less = func(p, q telegraph.Page) bool { return p.Views > q.Views }
+	case "title":
+		less = func(p, q telegraph.Page) bool { return p.Title < q.Title }
+	default:
+		fmt.Fprintf(a.stderr, "unknown sort order %q, expected views or title\n", sortBy)
+		return errUsage
+	}
+
+	token, _, err := a.token(&opts)
+	if err != nil {
+		return err
+	}
+	pages, err := a.client(&opts).GetAllPages(a.ctx, token)
+	if err != nil {
+		return err
+	}
+
+	if less != nil {
+		sort.SliceStable(pages, func(i, j int) bool { return less(pages[i], pages[j]) })
+	}
+	if limit > 0 && limit < len(pages) {
+		pages = pages[:limit]
+	}
+
+	if opts.json {
+		return a.printJSON(pages)
+	}
+	tw := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PATH\tVIEWS\tTITLE")
+	for _, page := range pages {
+		fmt.Fprintf(tw, "%s\t%d\t%s\n", page.Path, page.Views, page.Title)
+	}
+	return tw.Flush()
+}
+
+func pageGet(a *app, args []string) error {
+	var opts options
+	var format string
+	fs := a.flags("page get", &opts)
+	fs.StringVar(&format, "format", "md", "output format: html, md or json")
+	fs.Usage = func() {
+		fmt.Fprintln(a.stderr, "Usage: telegraph page get [flags] PATH|URL")
+		fs.PrintDefaults()
+	}
+	if err := a.parse(fs, args, 1); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+	if opts.json {
+		format = "json"
+	}
+	if format != "html" && format != "md" && format != "json" {
+		fmt.Fprintf(a.stderr, "unknown format %q, expected html, md or json\n", format)
+		return errUsage
+	}
+
+	page, err := a.client(&opts).GetPageWith(a.ctx, telegraph.GetPageRequest{
+		Path:          pagePath(fs.Arg(0)),
+		ReturnContent: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "json":
+		return a.printJSON(page)
+	case "html":
+		fmt.Fprint(a.stdout, frontMatter(page), telegraph.ContentToHTML(page.Content), "\n")
+	default:
+		fmt.Fprint(a.stdout, frontMatter(page), "\n", telegraph.ContentToMarkdown(page.Content))
+	}
+	return nil
+}
+
+func views(a *app, args []string) error {
+	var opts options
+	var from, to, by string
+	var spark bool
+	fs := a.flags("views", &opts)
+	fs.StringVar(&from, "from", "", "first day to count views for, as YYYY-MM-DD")
+	fs.StringVar(&to, "to", "", "last day to count views for, as YYYY-MM-DD (default today when --from is set)")
+	fs.StringVar(&by, "by", "day", "count views per day or hour")
+	fs.BoolVar(&spark, "sparkline", false, "print a sparkline instead of a table")
+	fs.Usage = func() {
+		fmt.Fprintln(a.stderr, "Usage: telegraph views [flags] PATH|URL")
+		fs.PrintDefaults()
+	}
+	if err := a.parse(fs, args, 1); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 || (by != "day" && by != "hour") {
+		fs.Usage()
+		return errUsage
+	}
+
+	ctx := a.ctx
+	client := a.client(&opts)
+	path := pagePath(fs.Arg(0))
+
+	if from == "" && to == "" {
+		total, err := client.GetViewsWith(ctx, telegraph.GetViewsRequest{Path: path})
+		if err != nil {
+			return err
+		}
+		if opts.json {
+			return a.printJSON(total)
+		}
+		fmt.Fprintln(a.stdout, total.Views)
+		return nil
+	}
+
+	start, end, err := dateRange(from, to)
+	if err != nil {
+		fmt.Fprintln(a.stderr, err)
+		return errUsage
+	}
+	counts, err := viewCounts(ctx, client, path, start, end, by == "hour")
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case opts.json:
+		return a.printJSON(counts)
+	case spark:
+		values := make([]int, len(counts))
+		total, peak := 0, 0
+		for i, count := range counts {
+			values[i] = count.Views
+			total += count.Views
+			peak = max(peak, count.Views)
+		}
+		fmt.Fprintf(a.stdout, "%s %s..%s total %d, max %d\n", sparkline(values), counts[0].Time, counts[len(counts)-1].Time, total, peak)
+		return nil
+	}
+	tw := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tVIEWS")
+	for _, count := range counts {
+		fmt.Fprintf(tw, "%s\t%d\n", count.Time, count.Views)
+	}
+	return tw.Flush()
+}
+
+// viewCount is the number of views in a day or an hour
+type viewCount struct {
+	Time  string `json:"time"`
+	Views int    `json:"views"`
+}
+
+// viewCounts requests the views of path for every day, or every hour, from
+// start to end
+func viewCounts(ctx context.Context, client *telegraph.Client, path string, start, end time.Time, hourly bool) ([]viewCount, error) {
+	days := int(end.Sub(start).Hours()/24) + 1
+	requests := days
+	if hourly {
+		requests *= 24
+	}
+	if requests > maxViewRequests {
+		return nil, fmt.Errorf("range needs %d requests, more than %d; choose a shorter range", requests, maxViewRequests)
+	}
+
+	var counts []viewCount
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		req := telegraph.GetViewsRequest{Path: path, Year: day.Year(), Month: int(day.Month()), Day: day.Day()}
+		if !hourly {
+			views, err := client.GetViewsWith(ctx, req)
+			if err != nil {
+				return nil, err
+			}
+			counts = append(counts, viewCount{Time: day.Format(dateLayout), Views: views.Views})
+			continue
+		}
+		for hour := 0; hour < 24; hour++ {
+			req.Hour = telegraph.Int(hour)
+			views, err := client.GetViewsWith(ctx, req)
+			if err != nil {
+				return nil, err
+			}
+			counts = append(counts, viewCount{Time: fmt.Sprintf("%s %02d:00", day.Format(dateLayout), hour), Views: views.Views})
+		}
+	}
+	return counts, nil
+}
+
+// dateRange parses the --from and --to dates. A missing end is today and a
+// missing start is the end.
+func dateRange(from, to string) (time.Time, time.Time, error) {
+	end := time.Now().UTC().Truncate(24 * time.Hour)
+	if to != "" {
+		var err error
+		if end, err = time.Parse(dateLayout, to); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid --to date %q, expected YYYY-MM-DD", to)
+		}
+	}
+	start := end
+	if from != "" {
+		var err error
+		if start, err = time.Parse(dateLayout, from); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid --from date %q, expected YYYY-MM-DD", from)
+		}
+	}
+	if start.After(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("--from %s is after --to %s", start.Format(dateLayout), end.Format(dateLayout))
+	}
+	return start, end, nil
+}
+
+// sparkline draws values as bars scaled to the largest value
+func sparkline(values []int) string {
+	peak := 0
+	for _, v := range values {
+		peak = max(peak, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		i := 0
+		if peak > 0 {
+			i = v * (len(sparks) - 1) / peak
+		}
+		b.WriteRune(sparks[i])
+	}
+	return b.String()
+}
+
+// pagePath returns the path of a page given by its path or URL
+func pagePath(arg string) string {
+	if u, err := url.Parse(arg); err == nil && u.Host != "" {
+		arg = u.Path
+	}
+	return strings.Trim(arg, "/")
+}
+
+// frontMatter returns the front matter describing page, which the publish
+// command reads back
+func frontMatter(page *telegraph.Page) string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "title: %q\n", page.Title)
+	if page.AuthorName != "" {
+		fmt.Fprintf(&b, "author: %q\n", page.AuthorName)
+	}
+	if page.AuthorURL != "" {
+		fmt.Fprintf(&b, "author_url: %q\n", page.AuthorURL)
+	}
+	fmt.Fprintf(&b, "url: %q\n", page.URL)
+	b.WriteString("---\n")
+	return b.String()
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPageCommands(t *testing.T) {
+	server, rec := apiServer(t, map[string]string{
+		"getPageList": `{"total_count":2,"pages":[{"path":"Old-01-01","title":"Old","views":9},{"path":"New-02-02","title":"New","views":1}]}`,
+		"getPage": `{"path":"Hello-10-19","url":"https://telegra.ph/Hello-10-19","title":"Say \"Hello\"","author_name":"Anonymous",` +
+			`"content":[{"tag":"p","children":["Hi ",{"tag":"b","children":["there"]}]}]}`,
+		"getViews": `{"views":4}`,
+	})
+	a, stdout, stderr := testApp(t, server, map[string]string{envToken: "token"})
+
+	tests := []struct {
+		name      string
+		args      []string
+		want      string
+		wantCalls int
+	}{
+		{
+			name:      "List sorted by views",
+			args:      []string{"pages", "list", "--sort", "views"},
+			want:      "PATH       VIEWS  TITLE\nOld-01-01  9      Old\nNew-02-02  1      New\n",
+			wantCalls: 1,
+		},
+		{
+			name:      "Get as Markdown",
+			args:      []string{"page", "get", "https://telegra.ph/Hello-10-19"},
+			want:      "---\ntitle: \"Say \\\"Hello\\\"\"\nauthor: \"Anonymous\"\nurl: \"https://telegra.ph/Hello-10-19\"\n---\n\nHi **there**\n",
+			wantCalls: 1,
+		},
+		{
+			name:      "Get as HTML",
+			args:      []string{"page", "get", "--format", "html", "Hello-10-19"},
+			want:      "<p>Hi <b>there</b></p>\n",
+			wantCalls: 1,
+		},
+		{
+			name:      "Total views",
+			args:      []string{"views", "Hello-10-19"},
+			want:      "4\n",
+			wantCalls: 1,
+		},
+		{
+			name:      "Views per day",
+			args:      []string{"views", "--from", "2024-02-28", "--to", "2024-03-01", "Hello-10-19"},
+			want:      "TIME        VIEWS\n2024-02-28  4\n2024-02-29  4\n2024-03-01  4\n",
+			wantCalls: 3,
+		},
+		{
+			name:      "Hourly sparkline",
+			args:      []string{"views", "--from", "2024-02-28", "--to", "2024-02-28", "--by", "hour", "--sparkline", "Hello-10-19"},
+			want:      "████████████████████████ 2024-02-28 00:00..2024-02-28 23:00 total 96, max 4\n",
+			wantCalls: 24,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout.Reset()
+			stderr.Reset()
+			if code := a.run(tt.args); code != exitOK {
+				t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, stderr)
+			}
+			if !strings.HasSuffix(stdout.String(), tt.want) {
+				t.Errorf("Expected output ending with:\n%s\ngot:\n%s", tt.want, stdout)
+			}
+			if calls := rec.methods(); len(calls) != tt.wantCalls {
+				t.Errorf("Expected %d requests, got %v", tt.wantCalls, calls)
+			}
+		})
+	}
+
+	if got := rec.body("getViews")["hour"]; got != float64(23) {
+		t.Errorf("Expected the last request for hour 23, got %v", got)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	if got := sparkline([]int{0, 1, 2, 4, 8}); got != "▁▁▂▄█" {
+		t.Errorf("Expected '▁▁▂▄█', got '%s'", got)
+	}
+	if got := sparkline([]int{0, 0}); got != "▁▁" {
+		t.Errorf("Expected '▁▁', got '%s'", got)
+	}
+}
+
+func TestDateRangeErrors(t *testing.T) {
+	tests := []struct{ from, to string }{
+		{"2024-13-01", ""},
+		{"", "tomorrow"},
+		{"2024-03-02", "2024-03-01"},
+	}
+	for _, tt := range tests {
+		if _, _, err := dateRange(tt.from, tt.to); err == nil {
+			t.Errorf("Expected error for --from %q --to %q, got nil", tt.from, tt.to)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/smirnoffmg/telegraph"
+)
+
+// Timeouts of the preview server
+const (
+	previewReadTimeout     = 10 * time.Second
+	previewShutdownTimeout = 5 * time.Second
+)
+
+func preview(a *app, args []string) error {
+	var addr string
+	flags := flag.NewFlagSet("preview", flag.ContinueOnError)
+	flags.StringVar(&addr, "addr", "localhost:8080", "address to serve the preview on")
+	flags.Usage = func() {
+		fmt.Fprintln(a.stderr, "Usage: telegraph preview [flags] FILE|DIR")
+		flags.PrintDefaults()
+	}
+	if err := a.parse(flags, args, 1); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errUsage
+	}
+
+	handler, err := previewHandler(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	server := &http.Server{Handler: handler, ReadHeaderTimeout: previewReadTimeout}
+	go func() {
+		<-a.ctx.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), previewShutdownTimeout)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+	}()
+
+	fmt.Fprintf(a.stderr, "previewing %s at http://%s/, press Ctrl+C to stop\n", flags.Arg(0), listener.Addr())
+	if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// previewHandler serves the preview of the file at root, or of the files in
+// the directory root with an index at /. Other files, such as local images,
+// are served as they are.
+func previewHandler(root string) (http.Handler, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	dir := root
+	if !info.IsDir() {
+		if !isSource(root) {
+			return nil, fmt.Errorf("%s: %w", root, errUnsupportedFile)
+		}
+		dir = filepath.Dir(root)
+	}
+
+	articles := telegraph.PreviewHandler(func(p string) (*telegraph.Article, error) {
+		switch {
+		case !info.IsDir() && p == "/":
+			return loadArticle(root)
+		case info.IsDir() && p == "/":
+			return indexArticle(dir)
+		case info.IsDir():
+			return loadArticle(filepath.Join(dir, filepath.FromSlash(path.Clean(p))))
+		}
+		return nil, fmt.Errorf("%s: %w", p, fs.ErrNotExist)
+	})
+	files := http.FileServer(http.Dir(dir))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" || isSource(r.URL.Path) {
+			articles.ServeHTTP(w, r)
+			return
+		}
+		files.ServeHTTP(w, r)
+	}), nil
+}
+
+// loadArticle loads the document at path as an article dated by the last
+// change of the file
+func loadArticle(path string) (*telegraph.Article, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := loadDocument(path)
+	if err != nil {
+		return nil, err
+	}
+	return &telegraph.Article{
+		Title:      doc.title,
+		AuthorName: doc.authorName,
+		AuthorURL:  doc.authorURL,
+		Date:       info.ModTime(),
+		Content:    doc.content,
+	}, nil
+}
+
+// indexArticle returns an article linking to the documents in dir
+func indexArticle(dir string) (*telegraph.Article, error) {
+	docs, err := loadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var items []telegraph.Node
+	for _, doc := range docs {
+		rel, err := filepath.Rel(dir, doc.path)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, telegraph.NodeElement{Tag: "li", Children: []telegraph.Node{
+			telegraph.NodeElement{Tag: "a", Attrs: map[string]string{"href": filepath.ToSlash(rel)}, Children: []telegraph.Node{doc.title}},
+		}})
+	}
+	return &telegraph.Article{
+		Title:   filepath.Base(dir),
+		Content: []telegraph.Node{telegraph.NodeElement{Tag: "ul", Children: items}},
+	}, nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPreviewHandler(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "index.md"), "# Index\n\nSee the [guide](guide.md).\n")
+	writeFile(t, filepath.Join(dir, "guide.md"), "---\ntitle: Guide\nauthor: Anonymous\n---\n\n![A cat](cat.png)\n")
+	writeFile(t, filepath.Join(dir, "cat.png"), pngHeader)
+
+	tests := []struct {
+		name       string
+		root       string
+		path       string
+		wantStatus int
+		want       string
+	}{
+		{"Directory index", dir, "/", http.StatusOK, `<li><a href="guide.md">Guide</a></li><li><a href="index.md">Index</a></li>`},
+		{"Document in directory", dir, "/guide.md", http.StatusOK, `<figure><img src="cat.png"><figcaption>A cat</figcaption></figure>`},
+		{"Image in directory", dir, "/cat.png", http.StatusOK, pngHeader},
+		{"Missing document", dir, "/missing.md", http.StatusNotFound, "no such file"},
+		{"Single file", filepath.Join(dir, "index.md"), "/", http.StatusOK, "<h1>Index</h1>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, err := previewHandler(tt.root)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			body, _ := io.ReadAll(rec.Body)
+			if rec.Code != tt.wantStatus || !strings.Contains(string(body), tt.want) {
+				t.Errorf("Expected status %d with %q, got %d:\n%s", tt.wantStatus, tt.want, rec.Code, body)
+			}
+		})
+	}
+
+	if _, err := previewHandler(filepath.Join(dir, "cat.png")); err == nil {
+		t.Error("Expected error previewing an image, got nil")
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/smirnoffmg/telegraph"
+)
+
+// Elements whose src may refer to a local file to upload
+var mediaSrcTags = map[string]struct{}{
+	"img":   {},
+	"video": {},
+}
+
+func publish(a *app, args []string) error {
+	var opts options
+	var title, authorName, authorURL, statePath string
+	var dryRun bool
+	fs := a.flags("publish", &opts)
+	fs.StringVar(&title, "title", "", "page title (default from front matter, the leading heading or the file name)")
+	fs.StringVar(&authorName, "author-name", "", "author name (default from front matter)")
+	fs.StringVar(&authorURL, "author-url", "", "author link (default from front matter)")
+	fs.StringVar(&statePath, "state", "", "state file remembering published pages (default "+stateFileName+" next to the file)")
+	fs.BoolVar(&dryRun, "dry-run", false, "print the page content as JSON instead of publishing it")
+	fs.Usage = func() {
+		fmt.Fprintln(a.stderr, "Usage: telegraph publish [flags] FILE.md|FILE.html")
+		fs.PrintDefaults()
+	}
+	if err := a.parse(fs, args, 1); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+
+	doc, err := loadDocument(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	doc.title = firstOf(title, doc.title)
+	doc.authorName = firstOf(authorName, doc.authorName)
+	doc.authorURL = firstOf(authorURL, doc.authorURL)
+
+	if dryRun {
+		fmt.Fprintf(a.stderr, "title: %s\n", doc.title)
+		return a.printContent(doc.content)
+	}
+
+	token, _, err := a.token(&opts)
+	if err != nil {
+		return err
+	}
+	if statePath == "" {
+		statePath = filepath.Join(filepath.Dir(doc.path), stateFileName)
+	}
+	st, err := loadState(statePath)
+	if err != nil {
+		return err
+	}
+
+	client := a.client(&opts)
+	result, err := publishDocument(a.ctx, telegraph.NewPublisher(client.WithToken(token), st), client, st, doc)
+	if result != nil {
+		// The page is printed even if its state was not saved, so it can be found
+		if printErr := a.printResult(&opts, doc.path, result); printErr != nil {
+			return errors.Join(err, printErr)
+		}
+	}
+	return err
+}
+
+// publishDocument uploads the local media of doc and publishes it
+func publishDocument(ctx context.Context, publisher *telegraph.Publisher, client *telegraph.Client,
+	st *state, doc *document,
+) (*telegraph.PublishResult, error) {
+	content, err := uploadMedia(ctx, client, st, filepath.Dir(doc.path), doc.content)
+	if err != nil {
+		return nil, err
+	}
+	if size := telegraph.ContentSize(content); size > telegraph.MaxContentSize {
+		return nil, fmt.Errorf("%s: %w: %d bytes, the limit is %d", doc.path, telegraph.ErrContentTooLarge, size, telegraph.MaxContentSize)
+	}
+
+	id, err := st.id(doc.path)
+	if err != nil {
+		return nil, err
+	}
+	return publisher.Publish(ctx, telegraph.Document{
+		ID:         id,
+		Title:      doc.title,
+		AuthorName: doc.authorName,
+		AuthorURL:  doc.authorURL,
+		Content:    content,
+	})
+}
+
+// printContent prints content as JSON and its size against the limit
+func (a *app) printContent(content []telegraph.Node) error {
+	if err := a.printJSON(content); err != nil {
+		return err
+	}
+
+	size := telegraph.ContentSize(content)
+	fmt.Fprintf(a.stderr, "size: %d of %d bytes (%.1f%%)\n", size, telegraph.MaxContentSize,
+		float64(size)*100/telegraph.MaxContentSize)
+	if size > telegraph.MaxContentSize {
+		return fmt.Errorf("%w: %d bytes over the limit", telegraph.ErrContentTooLarge, size-telegraph.MaxContentSize)
+	}
+	return nil
+}
+
+// printResult prints the URL of a published page, or the whole result as JSON
+func (a *app) printResult(opts *options, source string, result *telegraph.PublishResult) error {
+	if opts.json {
+		return a.printJSON(map[string]string{
+			"file":   source,
+			"action": result.Action.String(),
+			"path":   result.Page.Path,
+			"url":    result.Page.URL,
+		})
+	}
+	fmt.Fprintf(a.stderr, "%s: %s\n", source, result.Action)
+	fmt.Fprintln(a.stdout, result.Page.URL)
+	return nil
+}
+
+// uploadMedia returns a copy of nodes with the src of img and video elements
+// referring to local files, relative to dir, replaced by the src of the
+// uploaded files. Files uploaded before, as recorded in st, are not uploaded
+// again.
+func uploadMedia(ctx context.Context, client *telegraph.Client, st *state, dir string, nodes []telegraph.Node) ([]telegraph.Node, error) {
+	out := make([]telegraph.Node, len(nodes))
+	for i, n := range nodes {
+		elem, ok := n.(telegraph.NodeElement)
+		if !ok {
+			out[i] = n
+			continue
+		}
+
+		if src := elem.Attrs["src"]; src != "" && isLocalSrc(src) {
+			if _, ok := mediaSrcTags[elem.Tag]; ok {
+				uploaded, err := uploadLocal(ctx, client, st, dir, src)
+				if err != nil {
+					return nil, err
+				}
+				attrs := make(map[string]string, len(elem.Attrs))
+				for key, value := range elem.Attrs {
+					attrs[key] = value
+				}
+				attrs["src"] = uploaded
+				elem.Attrs = attrs
+			}
+		}
+
+		children, err := uploadMedia(ctx, client, st, dir, elem.Children)
+		if err != nil {
+			return nil, err
+		}
+		elem.Children = children
+		out[i] = elem
+	}
+	return out, nil
+}
+
+// uploadLocal uploads the file src refers to, unless it was uploaded before
+func uploadLocal(ctx context.Context, client *telegraph.Client, st *state, dir, src string) (string, error) {
+	path, err := url.PathUnescape(src)
+	if err != nil {
+		path = src
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, filepath.FromSlash(path))
+	}
+
+	hash, err := fileHash(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read media %s: %w", src, err)
+	}
+	if uploaded, ok := st.Uploads[hash]; ok {
+		return uploaded, nil
+	}
+
+	uploaded, err := client.UploadFile(ctx, path)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	st.Uploads[hash] = uploaded
+	if err := st.save(); err != nil {
+		return "", err
+	}
+	return uploaded, nil
+}
+
+// isLocalSrc reports whether src refers to a local file rather than a URL or
+// a file already on Telegraph
+func isLocalSrc(src string) bool {
+	u, err := url.Parse(src)
+	if err != nil || u.Scheme != "" || u.Host != "" {
+		return false
+	}
+	if strings.HasPrefix(src, "/") {
+		// Absolute paths are Telegraph paths such as /file/... unless they
+		// name an existing local file
+		_, err := os.Stat(src)
+		return err == nil
+	}
+	return true
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// pngHeader is enough of a PNG file for its type to be detected
+const pngHeader = "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+
+const testPageResult = `{"path":"Hello-10-19","url":"https://telegra.ph/Hello-10-19","title":"Hello"}`
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func TestPublish(t *testing.T) {
+	server, rec := apiServer(t, map[string]string{
+		"createPage": testPageResult,
+		"editPage":   testPageResult,
+	})
+	a, stdout, stderr := testApp(t, server, map[string]string{envToken: "token"})
+
+	dir := t.TempDir()
+	article := filepath.Join(dir, "article.md")
+	writeFile(t, filepath.Join(dir, "cat.png"), pngHeader)
+	writeFile(t, article, "---\ntitle: \"Hello\"\nauthor: Anonymous\n---\n\nSome *text*.\n\n![A cat](cat.png)\n")
+
+	steps := []struct {
+		name      string
+		edit      string
+		wantCalls []string
+		wantLog   string
+	}{
+		{"First publish creates", "", []string{"upload", "createPage"}, "created"},
+		{"Unchanged file is skipped", "", nil, "unchanged"},
+		{"Changed file is edited", "---\ntitle: Hello\n---\n\nOther text.\n\n![A cat](cat.png)\n", []string{"editPage"}, "updated"},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			if step.edit != "" {
+				writeFile(t, article, step.edit)
+			}
+			stdout.Reset()
+			stderr.Reset()
+
+			if code := a.run([]string{"publish", article}); code != exitOK {
+				t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, stderr)
+			}
+			if calls := rec.methods(); !reflect.DeepEqual(calls, step.wantCalls) {
+				t.Errorf("Expected calls %v, got %v", step.wantCalls, calls)
+			}
+			if got := strings.TrimSpace(stdout.String()); got != "https://telegra.ph/Hello-10-19" {
+				t.Errorf("Expected the page URL, got '%s'", got)
+			}
+			if !strings.Contains(stderr.String(), step.wantLog) {
+				t.Errorf("Expected '%s' in the log, got '%s'", step.wantLog, stderr)
+			}
+		})
+	}
+
+	created := rec.body("createPage")
+	if created["title"] != "Hello" || created["author_name"] != "Anonymous" {
+		t.Errorf("Expected title and author from front matter, got %v", created)
+	}
+	content, _ := json.Marshal(created["content"])
+	if !strings.Contains(string(content), uploadSrc) {
+		t.Errorf("Expected the uploaded image in content, got %s", content)
+	}
+	if _, err := os.Stat(filepath.Join(dir, stateFileName)); err != nil {
+		t.Errorf("Expected a state file, got %v", err)
+	}
+}
+
+func TestPublishDryRun(t *testing.T) {
+	server, rec := apiServer(t, nil)
+	a, stdout, stderr := testApp(t, server, nil)
+
+	article := filepath.Join(t.TempDir(), "article.md")
+	writeFile(t, article, "# Heading Title\n\nHello **world**\n")
+
+	if code := a.run([]string{"publish", "--dry-run", article}); code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, stderr)
+	}
+	if calls := rec.methods(); len(calls) != 0 {
+		t.Errorf("Expected no requests, got %v", calls)
+	}
+
+	want := `[{"tag":"p","children":["Hello ",{"tag":"strong","children":["world"]}]}]`
+	var content interface{}
+	if err := json.Unmarshal(stdout.Bytes(), &content); err != nil {
+		t.Fatalf("Expected JSON output, got %v", err)
+	}
+	var wantContent interface{}
+	_ = json.Unmarshal([]byte(want), &wantContent)
+	if !reflect.DeepEqual(content, wantContent) {
+		t.Errorf("Expected %s, got %s", want, stdout)
+	}
+	if !strings.Contains(stderr.String(), "size: 73 of 65536 bytes") {
+		t.Errorf("Expected the content size, got '%s'", stderr)
+	}
+}
+
+func TestParseFrontMatter(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		wantMeta map[string]string
+		wantBody string
+	}{
+		{"No front matter", "# Title\n", map[string]string{}, "# Title\n"},
+		{"Quoted values", "---\nTitle: 'A: B'\nauthor_url: https://example.com\n---\nBody", map[string]string{"title": "A: B", "author_url": "https://example.com"}, "Body"},
+		{"Unclosed", "---\ntitle: x\n", map[string]string{}, "---\ntitle: x\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta, body := parseFrontMatter(tt.src)
+			if !reflect.DeepEqual(meta, tt.wantMeta) {
+				t.Errorf("Expected %v, got %v", tt.wantMeta, meta)
+			}
+			if body != tt.wantBody {
+				t.Errorf("Expected body %q, got %q", tt.wantBody, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/smirnoffmg/telegraph"
+)
+
+// stateFileName is the name of the state file kept next to published files
+const stateFileName = ".telegraph.json"
+
+// state remembers the pages files were published to and the files uploaded
+// for them, so republishing edits the same pages and skips unchanged
+// uploads. It is a telegraph.StateStore keyed by file paths relative to the
+// directory of the state file; unlike telegraph.FileStateStore it keeps the
+// uploads in the same file.
+type state struct {
+	path    string
+	Pages   map[string]telegraph.PublishState `json:"pages"`
+	Uploads map[string]string                 `json:"uploads"` // SHA-256 of a file to its src
+}
+
+// loadState reads the state file at path, which may not exist yet
+func loadState(path string) (*state, error) {
+	st := &state{path: path}
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("failed to read state: %w", err)
+	default:
+		if err := json.Unmarshal(data, st); err != nil {
+			return nil, fmt.Errorf("failed to decode state %s: %w", path, err)
+		}
+	}
+	if st.Pages == nil {
+		st.Pages = make(map[string]telegraph.PublishState)
+	}
+	if st.Uploads == nil {
+		st.Uploads = make(map[string]string)
+	}
+	return st, nil
+}
+
+// Get returns the state of the page published from the file with the given ID
+func (s *state) Get(id string) (telegraph.PublishState, bool, error) {
+	page, ok := s.Pages[id]
+	return page, ok, nil
+}
+
+// Put records the page published from the file with the given ID
+func (s *state) Put(id string, page telegraph.PublishState) error {
+	s.Pages[id] = page
+	return s.save()
+}
+
+// id returns the key of the file at path
+func (s *state) id(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	dir, err := filepath.Abs(filepath.Dir(s.path))
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(dir, abs)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// save writes the state file, replacing it atomically
+func (s *state) save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+	if err := telegraph.WriteFileAtomic(s.path, data); err != nil {
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	return nil
+}
+
+// fileHash returns the SHA-256 of the file at path
+func fileHash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
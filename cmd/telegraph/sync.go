@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/smirnoffmg/telegraph"
+)
+
+// syncResult is the outcome of syncing one file
+type syncResult struct {
+	File   string `json:"file"`
+	Action string `json:"action"`
+	URL    string `json:"url"`
+}
+
+func syncDir(a *app, args []string) error {
+	var opts options
+	var manifest string
+	flags := a.flags("sync", &opts)
+	flags.StringVar(&manifest, "manifest", "", "manifest of published files (default "+stateFileName+" in the directory)")
+	flags.Usage = func() {
+		fmt.Fprintln(a.stderr, "Usage: telegraph sync [flags] DIR")
+		flags.PrintDefaults()
+	}
+	if err := a.parse(flags, args, 1); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errUsage
+	}
+	dir := flags.Arg(0)
+	if manifest == "" {
+		manifest = filepath.Join(dir, stateFileName)
+	}
+
+	docs, err := loadDir(dir)
+	if err != nil {
+		return err
+	}
+	token, _, err := a.token(&opts)
+	if err != nil {
+		return err
+	}
+	st, err := loadState(manifest)
+	if err != nil {
+		return err
+	}
+
+	client := a.client(&opts)
+	s := &syncer{
+		ctx:       a.ctx,
+		client:    client,
+		publisher: telegraph.NewPublisher(client.WithToken(token), st),
+		state:     st,
+		docs:      make(map[string]*document, len(docs)),
+	}
+	for _, doc := range docs {
+		id, err := st.id(doc.path)
+		if err != nil {
+			return err
+		}
+		s.docs[id] = doc
+	}
+
+	results, err := s.run()
+	if err != nil {
+		return err
+	}
+
+	for id := range st.Pages {
+		if _, ok := s.docs[id]; !ok {
+			fmt.Fprintf(a.stderr, "%s: no longer in the directory, its page is kept\n", id)
+		}
+	}
+	if opts.json {
+		return a.printJSON(results)
+	}
+	tw := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FILE\tACTION\tURL")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", r.File, r.Action, r.URL)
+	}
+	return tw.Flush()
+}
+
+// syncer publishes a set of documents linking to each other
+type syncer struct {
+	ctx       context.Context
+	client    *telegraph.Client
+	publisher *telegraph.Publisher
+	state     *state
+	docs      map[string]*document // documents by ID
+}
+
+// run publishes the documents in two passes. Links to documents are only
+// known once they are published, so the first pass creates the pages of new
+// documents and the second publishes all documents with every link rewritten;
+// pages created with unresolved links are edited then.
+func (s *syncer) run() ([]syncResult, error) {
+	ids := make([]string, 0, len(s.docs))
+	for id := range s.docs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	created := make(map[string]bool)
+	for _, id := range ids {
+		if _, ok := s.state.Pages[id]; ok {
+			continue
+		}
+		if _, err := s.publish(id); err != nil {
+			return nil, err
+		}
+		created[id] = true
+	}
+
+	results := make([]syncResult, 0, len(ids))
+	for _, id := range ids {
+		result, err := s.publish(id)
+		if err != nil {
+			return nil, err
+		}
+		action := result.Action.String()
+		if created[id] {
+			action = telegraph.PublishCreated.String()
+		}
+		results = append(results, syncResult{File: id, Action: action, URL: result.Page.URL})
+	}
+	return results, nil
+}
+
+// publish publishes a document with its links to other documents rewritten
+// to the URLs of their pages, as far as they are known
+func (s *syncer) publish(id string) (*telegraph.PublishResult, error) {
+	doc := *s.docs[id]
+	doc.content = telegraph.RewriteURLs(doc.content, func(tag, attr, value string) string {
+		if tag != "a" || attr != "href" {
+			return value
+		}
+		return s.resolveLink(id, value)
+	})
+	return publishDocument(s.ctx, s.publisher, s.client, s.state, &doc)
+}
+
+// resolveLink returns the URL of the page published from the document a
+// relative link in the document with the given ID refers to, or the link
+// itself if it does not refer to a published document
+func (s *syncer) resolveLink(id, href string) string {
+	u, err := url.Parse(href)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" || strings.HasPrefix(u.Path, "/") {
+		return href
+	}
+
+	target := path.Clean(path.Join(path.Dir(id), u.Path))
+	page, ok := s.state.Pages[target]
+	if _, isDoc := s.docs[target]; !ok || !isDoc {
+		return href
+	}
+	if u.Fragment != "" {
+		return page.URL + "#" + u.Fragment
+	}
+	return page.URL
+}
+
+// loadDir loads the documents in dir and its subdirectories, skipping
+// hidden files and directories
+func loadDir(dir string) ([]*document, error) {
+	var docs []*document
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !isSource(p) {
+			return nil
+		}
+		doc, err := loadDocument(p)
+		if err != nil {
+			return err
+		}
+		docs = append(docs, doc)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) == 0 {
+		return nil, fmt.Errorf("no Markdown or HTML files in %s", dir)
+	}
+	return docs, nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/smirnoffmg/telegraph"
+)
+
+func TestSync(t *testing.T) {
+	server, rec := apiServer(t, nil)
+	pages := make(map[string]map[string]interface{}) // pages by path
+	page := func(body map[string]interface{}) string {
+		path := body["path"]
+		if path == nil {
+			path = telegraph.Slug(body["title"].(string))
+		}
+		pages[path.(string)] = body
+		return fmt.Sprintf(`{"path":%q,"url":"https://telegra.ph/%s"}`, path, path)
+	}
+	rec.results = map[string]func(map[string]interface{}) string{"createPage": page, "editPage": page}
+	a, stdout, stderr := testApp(t, server, map[string]string{envToken: "token"})
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "guides"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "index.md"), "# Index\n\nSee the [guide](guides/setup.md#install).\n")
+	writeFile(t, filepath.Join(dir, "guides", "setup.md"), "# Setup\n\nBack to the [index](../index.md).\n")
+	writeFile(t, filepath.Join(dir, "notes.txt"), "not a document")
+
+	links := func(path string) string {
+		content, _ := json.Marshal(pages[path]["content"])
+		return string(content)
+	}
+
+	steps := []struct {
+		name      string
+		edit      func()
+		wantCalls []string
+		wantTable string
+	}{
+		{
+			name:      "First sync creates pages and links them",
+			edit:      func() {},
+			wantCalls: []string{"createPage", "createPage", "editPage"},
+			wantTable: "FILE             ACTION   URL\n" +
+				"guides/setup.md  created  https://telegra.ph/Setup\n" +
+				"index.md         created  https://telegra.ph/Index\n",
+		},
+		{
+			name:      "Second sync changes nothing",
+			edit:      func() {},
+			wantCalls: nil,
+		},
+		{
+			name: "Only changed files are edited",
+			edit: func() {
+				writeFile(t, filepath.Join(dir, "index.md"), "# Index\n\nRead the [guide](guides/setup.md).\n")
+			},
+			wantCalls: []string{"editPage"},
+		},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			step.edit()
+			stdout.Reset()
+			stderr.Reset()
+			if code := a.run([]string{"sync", dir}); code != exitOK {
+				t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, stderr)
+			}
+			if calls := rec.methods(); !reflect.DeepEqual(calls, step.wantCalls) {
+				t.Errorf("Expected calls %v, got %v", step.wantCalls, calls)
+			}
+			if step.wantTable != "" && stdout.String() != step.wantTable {
+				t.Errorf("Expected:\n%s\ngot:\n%s", step.wantTable, stdout)
+			}
+		})
+	}
+
+	if got := links("Setup"); !strings.Contains(got, `"href":"https://telegra.ph/Index"`) {
+		t.Errorf("Expected setup to link to the index page, got %s", got)
+	}
+	if got := links("Index"); !strings.Contains(got, `"href":"https://telegra.ph/Setup"`) {
+		t.Errorf("Expected index to link to the setup page, got %s", got)
+	}
+}
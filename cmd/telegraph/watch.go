@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/smirnoffmg/telegraph"
+	"github.com/smirnoffmg/telegraph/internal/watch"
+)
+
+// timeLayout is the layout of the times watch reports republishing at
+const timeLayout = "15:04:05"
+
+func watchFile(a *app, args []string) error {
+	var opts options
+	var pagePathArg, statePath string
+	var debounce, interval time.Duration
+	var poll bool
+	fs := a.flags("watch", &opts)
+	fs.StringVar(&pagePathArg, "path", "", "path or URL of the page to edit (default the page the file was published to)")
+	fs.StringVar(&statePath, "state", "", "state file remembering published pages (default "+stateFileName+" next to the file)")
+	fs.DurationVar(&debounce, "debounce", watch.DefaultDebounce, "how long the file must stay unchanged before it is republished")
+	fs.DurationVar(&interval, "interval", watch.DefaultInterval, "how often the file is checked when polling")
+	fs.BoolVar(&poll, "poll", false, "poll the file instead of using file system notifications")
+	fs.Usage = func() {
+		fmt.Fprintln(a.stderr, "Usage: telegraph watch [flags] FILE.md|FILE.html")
+		fs.PrintDefaults()
+	}
+	if err := a.parse(fs, args, 1); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+	file := fs.Arg(0)
+
+	token, _, err := a.token(&opts)
+	if err != nil {
+		return err
+	}
+	if statePath == "" {
+		statePath = filepath.Join(filepath.Dir(file), stateFileName)
+	}
+	st, err := loadState(statePath)
+	if err != nil {
+		return err
+	}
+	id, err := st.id(file)
+	if err != nil {
+		return err
+	}
+
+	// Watching only ever edits an existing page, so that every save does not
+	// leave a new page behind
+	if path := pagePath(pagePathArg); path != "" && st.Pages[id].Path != path {
+		st.Pages[id] = telegraph.PublishState{Path: path}
+	}
+	if _, ok := st.Pages[id]; !ok {
+		return fmt.Errorf("%s has no page to edit: publish it first or give --path", file)
+	}
+
+	changes, err := watch.Watch(a.ctx, []string{file}, watch.Options{Debounce: debounce, Interval: interval, Poll: poll})
+	if err != nil {
+		return err
+	}
+
+	client := a.client(&opts)
+	publisher := telegraph.NewPublisher(client.WithToken(token), st)
+	republish := func() {
+		doc, err := loadDocument(file)
+		var result *telegraph.PublishResult
+		if err == nil {
+			result, err = publishDocument(a.ctx, publisher, client, st, doc)
+		}
+		switch {
+		case a.ctx.Err() != nil:
+		case err != nil:
+			// Errors are reported without stopping, the next save may fix them
+			fmt.Fprintf(a.stderr, "%s error: %v\n", time.Now().Format(timeLayout), err)
+		default:
+			fmt.Fprintf(a.stdout, "%s %s %s\n", time.Now().Format(timeLayout), result.Action, result.Page.URL)
+		}
+	}
+
+	fmt.Fprintf(a.stderr, "watching %s, press Ctrl+C to stop\n", file)
+	republish()
+	for range changes {
+		republish()
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	server, rec := apiServer(t, map[string]string{
+		"createPage": testPageResult,
+		"editPage":   testPageResult,
+	})
+	a, stdout, stderr := testApp(t, server, map[string]string{envToken: "token"})
+
+	dir := t.TempDir()
+	article := filepath.Join(dir, "article.md")
+	writeFile(t, article, "# Hello\n\nFirst draft.\n")
+
+	if code := a.run([]string{"watch", article}); code != exitError {
+		t.Fatalf("Expected exit code %d without a page, got %d", exitError, code)
+	}
+	if !strings.Contains(stderr.String(), "no page to edit") {
+		t.Errorf("Expected missing page error, got %q", stderr)
+	}
+	stderr.Reset()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a.ctx = ctx
+	done := make(chan int)
+	go func() {
+		done <- a.run([]string{"watch", "--path", "https://telegra.ph/Hello-10-19", "--debounce", "50ms", article})
+	}()
+
+	// waitCalls waits for the calls made by the next publish
+	waitCalls := func(want []string) {
+		t.Helper()
+		var calls []string
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+			if calls = append(calls, rec.methods()...); len(calls) >= len(want) {
+				break
+			}
+		}
+		if !reflect.DeepEqual(calls, want) {
+			t.Fatalf("Expected calls %v, got %v", want, calls)
+		}
+	}
+
+	waitCalls([]string{"editPage"})
+	if path := rec.body("editPage")["path"]; path != "Hello-10-19" {
+		t.Errorf("Expected page Hello-10-19 to be edited, got %v", path)
+	}
+
+	writeFile(t, article, "# Hello\n\n"+strings.Repeat("Too long. ", 7000)+"\n")
+	time.Sleep(300 * time.Millisecond)
+	writeFile(t, article, "# Hello\n\nSecond draft.\n")
+	waitCalls([]string{"editPage"})
+
+	cancel()
+	if code := <-done; code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, stderr)
+	}
+	if calls := rec.methods(); len(calls) != 0 {
+		t.Errorf("Expected no more calls, got %v", calls)
+	}
+	if got := strings.Count(stdout.String(), "updated https://telegra.ph/Hello-10-19"); got != 2 {
+		t.Errorf("Expected 2 updates, got output %q", stdout)
+	}
+	if !strings.Contains(stderr.String(), "error: ") || !strings.Contains(stderr.String(), "content is too large") {
+		t.Errorf("Expected the oversized draft to be reported, got %q", stderr)
+	}
+}
@@ -2,9 +2,12 @@ package telegraph
 
 import (
 	"fmt"
+	"io"
+	"sort"
 	"strings"
 
 	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
 )
 
 // Allowed tags and attributes
@@ -39,19 +42,69 @@ var allowedAttrs = map[string]struct{}{
 	"href": {}, "src": {},
 }
 
-// domToNode converts an HTML node to a telegraph.Node
-func domToNode(n *html.Node) interface{} {
+// ConvertOptions controls how HTML is converted to content
+type ConvertOptions struct {
+	// Context is the element the HTML is parsed as the inner content of,
+	// "body" when empty. Use it to parse fragments that only make sense in a
+	// specific parent, e.g. "ul" for a list of li elements.
+	Context string
+	// Tables selects how table elements, which Telegraph does not support,
+	// are rendered. Tables are rendered as text by default.
+	Tables TableStrategy
+	// TableRenderer renders tables to images when Tables is TablesAsImage
+	TableRenderer TableRenderer
+}
+
+// ParseError describes a failure to read HTML input. Malformed markup is
+// repaired rather than rejected, so reading the input is the only way
+// parsing fails. The whole input is read before it is parsed.
+type ParseError struct {
+	Offset int64 // number of bytes read before reading failed
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("failed to read HTML after %d bytes: %v", e.Offset, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// countingReader counts the bytes read from r
+type countingReader struct {
+	r      io.Reader
+	offset int64
+}
+
+func (c *countingReader) Read(b []byte) (int, error) {
+	n, err := c.r.Read(b)
+	c.offset += int64(n)
+	return n, err
+}
+
+// converter turns parsed HTML nodes into telegraph.Nodes
+type converter struct {
+	opts ConvertOptions
+}
+
+// nodes converts an HTML node to telegraph.Nodes
+func (cv *converter) nodes(n *html.Node) ([]Node, error) {
 	if n.Type == html.TextNode {
-		return n.Data
+		return []Node{n.Data}, nil
 	}
 
 	if n.Type != html.ElementNode {
-		return nil
+		return nil, nil
+	}
+
+	if n.DataAtom == atom.Table {
+		return cv.table(n)
 	}
 
 	// Ensure the tag is allowed
 	if _, ok := allowedTags[n.Data]; !ok {
-		return nil
+		return nil, nil
 	}
 
 	nodeElement := NodeElement{
@@ -66,46 +119,123 @@ func domToNode(n *html.Node) interface{} {
 	}
 
 	for c := n.FirstChild; c != nil; c = c.NextSibling {
-		child := domToNode(c)
-		if child != nil {
-			nodeElement.Children = append(nodeElement.Children, child)
+		children, err := cv.nodes(c)
+		if err != nil {
+			return nil, err
 		}
+		nodeElement.Children = append(nodeElement.Children, children...)
 	}
 
-	return nodeElement
+	return []Node{nodeElement}, nil
 }
 
-// HTMLToContent transforms HTML string to a slice of telegraph.Nodes
-func HTMLToContent(htmlStr string) ([]Node, error) {
-	doc, err := html.Parse(strings.NewReader(htmlStr))
+// ConvertHTML reads HTML from r and transforms it to a slice of telegraph.Nodes.
+// All of r is read before parsing starts; failures to read it are reported
+// as a *ParseError.
+// The input is parsed as a fragment, so it may be a full document as well as
+// a piece of markup without html and body elements.
+// The result is normalized with NormalizeContent and links to supported media
+// are turned into embeds with EmbedContent.
+func ConvertHTML(r io.Reader, opts ConvertOptions) ([]Node, error) {
+	contextTag := opts.Context
+	if contextTag == "" {
+		contextTag = "body"
+	}
+	contextAtom := atom.Lookup([]byte(contextTag))
+	if contextAtom == 0 {
+		return nil, fmt.Errorf("unknown context element %q", contextTag)
+	}
+
+	cr := &countingReader{r: r}
+	nodes, err := html.ParseFragment(cr, &html.Node{
+		Type:     html.ElementNode,
+		Data:     contextTag,
+		DataAtom: contextAtom,
+	})
 	if err != nil {
-		return nil, err
+		return nil, &ParseError{Offset: cr.offset, Err: err}
 	}
 
-	var bodyNode *html.Node
-	var f func(*html.Node)
-	f = func(n *html.Node) {
-		if n.Type == html.ElementNode && n.Data == "body" {
-			bodyNode = n
-			return
-		}
-		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			f(c)
+	cv := &converter{opts: opts}
+	var content []Node
+	for _, n := range fragmentRoots(nodes) {
+		converted, err := cv.nodes(n)
+		if err != nil {
+			return nil, err
 		}
+		content = append(content, converted...)
 	}
-	f(doc)
 
-	if bodyNode == nil {
-		return nil, fmt.Errorf("no body element found")
-	}
+	return EmbedContent(NormalizeContent(content)), nil
+}
 
-	var content []Node
-	for c := bodyNode.FirstChild; c != nil; c = c.NextSibling {
-		node := domToNode(c)
-		if node != nil {
-			content = append(content, node)
+// fragmentRoots replaces document-level html and body elements, which
+// appear when parsing in the context of html, with their children
+func fragmentRoots(nodes []*html.Node) []*html.Node {
+	var roots []*html.Node
+	for _, n := range nodes {
+		if n.Type != html.ElementNode {
+			roots = append(roots, n)
+			continue
+		}
+		switch n.DataAtom {
+		case atom.Head:
+			continue
+		case atom.Html, atom.Body:
+			var children []*html.Node
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				children = append(children, c)
+			}
+			roots = append(roots, fragmentRoots(children)...)
+		default:
+			roots = append(roots, n)
 		}
 	}
+	return roots
+}
+
+// HTMLToContent transforms HTML string to a slice of telegraph.Nodes.
+// See ConvertHTML for details.
+func HTMLToContent(htmlStr string) ([]Node, error) {
+	return ConvertHTML(strings.NewReader(htmlStr), ConvertOptions{})
+}
 
-	return content, nil
+// ContentToHTML renders content as HTML with text and attribute values
+// escaped and attributes in alphabetical order
+func ContentToHTML(content []Node) string {
+	var b strings.Builder
+	renderHTML(&b, content)
+	return b.String()
+}
+
+// renderHTML writes nodes as HTML to b
+func renderHTML(b *strings.Builder, nodes []Node) {
+	for _, n := range nodes {
+		if s, ok := n.(string); ok {
+			b.WriteString(html.EscapeString(s))
+			continue
+		}
+		elem, ok := asElement(n)
+		if !ok {
+			continue
+		}
+
+		b.WriteString("<" + elem.Tag)
+		keys := make([]string, 0, len(elem.Attrs))
+		for key := range elem.Attrs {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(b, ` %s="%s"`, key, html.EscapeString(elem.Attrs[key]))
+		}
+		b.WriteString(">")
+
+		// Elements that are void in HTML have no end tag
+		if elem.Tag == "br" || elem.Tag == "hr" || elem.Tag == "img" {
+			continue
+		}
+		renderHTML(b, elem.Children)
+		b.WriteString("</" + elem.Tag + ">")
+	}
 }
@@ -2,6 +2,9 @@ package telegraph_test
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
+	"strings"
 	"testing"
 
 	"github.com/smirnoffmg/telegraph"
@@ -31,3 +34,103 @@ func TestHTMLToContent(t *testing.T) {
 		t.Errorf("Expected\n%s\ngot\n%s", testContentJSON, string(contentJSON))
 	}
 }
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+func TestConvertHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		context  string
+		wantJSON string
+	}{
+		{
+			name:     "Full document",
+			input:    `<!DOCTYPE html><html><head><title>T</title></head><body><p>Hi</p></body></html>`,
+			wantJSON: `[{"tag":"p","children":["Hi"]}]`,
+		},
+		{
+			name:     "Fragment without body",
+			input:    `<h3>Title</h3>text`,
+			wantJSON: `[{"tag":"h3","children":["Title"]},{"tag":"p","children":["text"]}]`,
+		},
+		{
+			name:     "List items in list context",
+			input:    `<li>a</li><li>b</li>`,
+			context:  "ul",
+			wantJSON: `[{"tag":"li","children":["a"]},{"tag":"li","children":["b"]}]`,
+		},
+		{
+			name:     "Document in html context",
+			input:    `<head><title>T</title></head><body><p>Hi</p></body>`,
+			context:  "html",
+			wantJSON: `[{"tag":"p","children":["Hi"]}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := telegraph.ConvertHTML(strings.NewReader(tt.input), telegraph.ConvertOptions{Context: tt.context})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			got, err := json.Marshal(content)
+			if err != nil {
+				t.Fatalf("Failed to marshal content: %v", err)
+			}
+			if string(got) != tt.wantJSON {
+				t.Errorf("Expected\n%s\ngot\n%s", tt.wantJSON, string(got))
+			}
+		})
+	}
+}
+
+func TestConvertHTMLErrors(t *testing.T) {
+	_, err := telegraph.ConvertHTML(strings.NewReader("<p>x</p>"), telegraph.ConvertOptions{Context: "no-such-tag"})
+	if err == nil {
+		t.Fatalf("Expected error for unknown context, got nil")
+	}
+
+	r := io.MultiReader(strings.NewReader("<p>one\ntwo"), failingReader{})
+	_, err = telegraph.ConvertHTML(r, telegraph.ConvertOptions{})
+	var parseErr *telegraph.ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected ParseError, got %v", err)
+	}
+	if parseErr.Offset != 10 {
+		t.Errorf("Expected the failure after 10 bytes, got %d", parseErr.Offset)
+	}
+}
+
+func TestContentToHTML(t *testing.T) {
+	content := []telegraph.Node{
+		telegraph.NodeElement{Tag: "p", Children: []telegraph.Node{
+			"a < b & ",
+			telegraph.NodeElement{Tag: "a", Attrs: map[string]string{"href": `https://example.com/?q="x"`}, Children: []telegraph.Node{"link"}},
+			telegraph.NodeElement{Tag: "br"},
+		}},
+		telegraph.NodeElement{Tag: "figure", Children: []telegraph.Node{
+			telegraph.NodeElement{Tag: "img", Attrs: map[string]string{"src": "/file/cat.png"}},
+			telegraph.NodeElement{Tag: "figcaption", Children: []telegraph.Node{"Cat"}},
+		}},
+	}
+
+	want := `<p>a &lt; b &amp; <a href="https://example.com/?q=&#34;x&#34;">link</a><br></p>` +
+		`<figure><img src="/file/cat.png"><figcaption>Cat</figcaption></figure>`
+	got := telegraph.ContentToHTML(content)
+	if got != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, got)
+	}
+
+	roundTrip, err := telegraph.HTMLToContent(got)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !telegraph.Equal(roundTrip, content) {
+		t.Errorf("Expected the HTML to convert back to the content, got diff:\n%s", telegraph.UnifiedDiff(content, roundTrip))
+	}
+}
@@ -0,0 +1,510 @@
+package telegraph
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Number of unchanged lines shown around changes by UnifiedDiff
+const diffContext = 3
+
+// ChangeKind is the kind of a Change
+type ChangeKind int
+
+const (
+	// NodeInserted means a node is present only in the new content
+	NodeInserted ChangeKind = iota
+	// NodeRemoved means a node is present only in the old content
+	NodeRemoved
+	// TextChanged means a text node has different text
+	TextChanged
+	// AttrChanged means an attribute of an element was added, removed or changed
+	AttrChanged
+)
+
+// String returns the name of the kind
+func (k ChangeKind) String() string {
+	switch k {
+	case NodeInserted:
+		return "inserted"
+	case NodeRemoved:
+		return "removed"
+	case TextChanged:
+		return "text changed"
+	case AttrChanged:
+		return "attr changed"
+	}
+	return fmt.Sprintf("ChangeKind(%d)", int(k))
+}
+
+// Change is a difference between two Node trees found by Diff
+type Change struct {
+	Kind ChangeKind
+	// Path holds the child indexes leading to the node from the top level of
+	// the new content. For removed nodes the last index refers to the old
+	// content instead.
+	Path []int
+	// Attr is the name of the attribute for AttrChanged
+	Attr string
+	// Old and New are the nodes before and after the change. For
+	// AttrChanged they hold the attribute values, which are empty when the
+	// attribute is absent.
+	Old Node
+	New Node
+}
+
+// String describes the change, e.g. `text changed at 0/1: "a" -> "b"`
+func (c Change) String() string {
+	path := make([]string, len(c.Path))
+	for i, index := range c.Path {
+		path[i] = strconv.Itoa(index)
+	}
+	at := strings.Join(path, "/")
+
+	switch c.Kind {
+	case NodeInserted:
+		return fmt.Sprintf("inserted at %s: %s", at, describeNode(c.New))
+	case NodeRemoved:
+		return fmt.Sprintf("removed at %s: %s", at, describeNode(c.Old))
+	case AttrChanged:
+		return fmt.Sprintf("attr %s changed at %s: %q -> %q", c.Attr, at, c.Old, c.New)
+	}
+	return fmt.Sprintf("%s at %s: %s -> %s", c.Kind, at, describeNode(c.Old), describeNode(c.New))
+}
+
+// Diff returns the structural changes that turn old into new. Children are
+// matched by a longest common subsequence; unmatched text nodes and elements
+// with the same tag at the same place are compared further instead of being
+// reported as removed and inserted.
+func Diff(old, new []Node) []Change {
+	return diffNodes(nil, old, new)
+}
+
+// Equal reports whether a and b render the same way. Both are normalized with
+// NormalizeContent first, so differences in whitespace, empty attributes,
+// adjacent text nodes or the node representation are ignored.
+func Equal(a, b []Node) bool {
+	return nodesEqual(NormalizeContent(a), NormalizeContent(b))
+}
+
+// UnifiedDiff renders the difference between old and new as a unified diff of
+// their outlines, with one line per element or text node. It returns an empty
+// string when there is no difference.
+func UnifiedDiff(old, new []Node) string {
+	oldLines := outline(old, 0, nil)
+	newLines := outline(new, 0, nil)
+	ops := editScript(len(oldLines), len(newLines), func(i, j int) bool { return oldLines[i] == newLines[j] })
+
+	type diffLine struct {
+		op       editOp
+		text     string
+		old, new int // lines of old and new content before this one
+	}
+	lines := make([]diffLine, 0, len(ops))
+	changed := false
+	i, j := 0, 0
+	for _, op := range ops {
+		line := diffLine{op: op, old: i, new: j}
+		switch op {
+		case opEqual:
+			line.text = " " + oldLines[i]
+			i++
+			j++
+		case opDelete:
+			line.text = "-" + oldLines[i]
+			i++
+			changed = true
+		case opInsert:
+			line.text = "+" + newLines[j]
+			j++
+			changed = true
+		}
+		lines = append(lines, line)
+	}
+	if !changed {
+		return ""
+	}
+
+	var out strings.Builder
+	out.WriteString("--- old\n+++ new\n")
+	for k := 0; k < len(lines); {
+		for k < len(lines) && lines[k].op == opEqual {
+			k++
+		}
+		if k == len(lines) {
+			break
+		}
+
+		// Extend the hunk over following changes separated by at most
+		// twice the context
+		start := max(k-diffContext, 0)
+		end := k
+		for end < len(lines) {
+			if lines[end].op != opEqual {
+				end++
+				continue
+			}
+			run := end
+			for run < len(lines) && lines[run].op == opEqual {
+				run++
+			}
+			if run == len(lines) || run-end > 2*diffContext {
+				end = min(end+diffContext, len(lines))
+				break
+			}
+			end = run
+		}
+
+		oldCount, newCount := 0, 0
+		for _, line := range lines[start:end] {
+			if line.op != opInsert {
+				oldCount++
+			}
+			if line.op != opDelete {
+				newCount++
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n",
+			hunkRange(lines[start].old, oldCount), hunkRange(lines[start].new, newCount))
+		for _, line := range lines[start:end] {
+			out.WriteString(line.text)
+			out.WriteByte('\n')
+		}
+		k = end
+	}
+	return out.String()
+}
+
+// hunkRange formats the range of a hunk header; an empty range refers to the
+// line before it
+func hunkRange(before, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
+
+// outline appends a line for every node in nodes to lines, indenting children
+// under their parents
+func outline(nodes []Node, depth int, lines []string) []string {
+	indent := strings.Repeat("  ", depth)
+	for _, n := range nodes {
+		if s, ok := n.(string); ok {
+			lines = append(lines, indent+strconv.Quote(s))
+			continue
+		}
+		elem, ok := asElement(n)
+		if !ok {
+			continue
+		}
+		lines = append(lines, indent+openTag(elem))
+		lines = outline(elem.Children, depth+1, lines)
+	}
+	return lines
+}
+
+// openTag returns the opening HTML tag of elem with its attributes in
+// alphabetical order
+func openTag(elem NodeElement) string {
+	keys := make([]string, 0, len(elem.Attrs))
+	for key := range elem.Attrs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString("<" + elem.Tag)
+	for _, key := range keys {
+		fmt.Fprintf(&b, " %s=%q", key, elem.Attrs[key])
+	}
+	b.WriteString(">")
+	return b.String()
+}
+
+// describeNode returns a short description of n for Change.String
+func describeNode(n Node) string {
+	if s, ok := n.(string); ok {
+		return strconv.Quote(s)
+	}
+	if elem, ok := asElement(n); ok {
+		return openTag(elem)
+	}
+	return fmt.Sprint(n)
+}
+
+// diffNodes compares the children old and new of the node at path
+func diffNodes(path []int, old, new []Node) []Change {
+	var changes []Change
+	var removed, inserted []int
+
+	// flush reports the unmatched nodes between two matched ones, comparing
+	// pairs of similar nodes in order
+	flush := func() {
+		k := 0
+		for ; k < len(removed) && k < len(inserted); k++ {
+			o, n := old[removed[k]], new[inserted[k]]
+			if similar(o, n) {
+				changes = append(changes, diffNode(childPath(path, inserted[k]), o, n)...)
+				continue
+			}
+			changes = append(changes,
+				Change{Kind: NodeRemoved, Path: childPath(path, removed[k]), Old: o},
+				Change{Kind: NodeInserted, Path: childPath(path, inserted[k]), New: n})
+		}
+		for _, i := range removed[k:] {
+			changes = append(changes, Change{Kind: NodeRemoved, Path: childPath(path, i), Old: old[i]})
+		}
+		for _, j := range inserted[k:] {
+			changes = append(changes, Change{Kind: NodeInserted, Path: childPath(path, j), New: new[j]})
+		}
+		removed, inserted = removed[:0], inserted[:0]
+	}
+
+	i, j := 0, 0
+	for _, op := range editScript(len(old), len(new), func(i, j int) bool { return nodeEqual(old[i], new[j]) }) {
+		switch op {
+		case opEqual:
+			flush()
+			i++
+			j++
+		case opDelete:
+			removed = append(removed, i)
+			i++
+		case opInsert:
+			inserted = append(inserted, j)
+			j++
+		}
+	}
+	flush()
+	return changes
+}
+
+// diffNode compares two similar nodes
+func diffNode(path []int, old, new Node) []Change {
+	if _, ok := old.(string); ok {
+		return []Change{{Kind: TextChanged, Path: path, Old: old, New: new}}
+	}
+
+	oldElem, _ := asElement(old)
+	newElem, _ := asElement(new)
+
+	keys := make([]string, 0, len(oldElem.Attrs)+len(newElem.Attrs))
+	for key := range oldElem.Attrs {
+		keys = append(keys, key)
+	}
+	for key := range newElem.Attrs {
+		if _, ok := oldElem.Attrs[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var changes []Change
+	for _, key := range keys {
+		if oldElem.Attrs[key] != newElem.Attrs[key] {
+			changes = append(changes, Change{
+				Kind: AttrChanged,
+				Path: path,
+				Attr: key,
+				Old:  oldElem.Attrs[key],
+				New:  newElem.Attrs[key],
+			})
+		}
+	}
+	return append(changes, diffNodes(path, oldElem.Children, newElem.Children)...)
+}
+
+// similar reports whether a and b are both text nodes or both elements with
+// the same tag
+func similar(a, b Node) bool {
+	_, aText := a.(string)
+	_, bText := b.(string)
+	if aText || bText {
+		return aText && bText
+	}
+	aElem, aOK := asElement(a)
+	bElem, bOK := asElement(b)
+	return aOK && bOK && aElem.Tag == bElem.Tag
+}
+
+// childPath returns a copy of path extended by index
+func childPath(path []int, index int) []int {
+	return append(append(make([]int, 0, len(path)+1), path...), index)
+}
+
+// nodesEqual reports whether a and b hold equal nodes
+func nodesEqual(a, b []Node) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !nodeEqual(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// nodeEqual reports whether a and b are the same node regardless of its
+// representation. Missing and empty attributes are considered equal.
+func nodeEqual(a, b Node) bool {
+	aText, aIsText := a.(string)
+	bText, bIsText := b.(string)
+	if aIsText || bIsText {
+		return aIsText && bIsText && aText == bText
+	}
+
+	aElem, aOK := asElement(a)
+	bElem, bOK := asElement(b)
+	if !aOK || !bOK {
+		return !aOK && !bOK && reflect.DeepEqual(a, b)
+	}
+	if aElem.Tag != bElem.Tag {
+		return false
+	}
+	// Missing attributes read as empty ones
+	for key, value := range aElem.Attrs {
+		if bElem.Attrs[key] != value {
+			return false
+		}
+	}
+	for key, value := range bElem.Attrs {
+		if aElem.Attrs[key] != value {
+			return false
+		}
+	}
+	return nodesEqual(aElem.Children, bElem.Children)
+}
+
+// editOp is a step of an edit script
+type editOp int
+
+const (
+	opEqual editOp = iota
+	opDelete
+	opInsert
+)
+
+// editScript returns the shortest sequence of steps turning a sequence of n
+// items into one of m items, where eq compares the i-th item of the first
+// sequence with the j-th item of the second. It is based on a longest common
+// subsequence after stripping the common prefix and suffix, found with
+// Hirschberg's algorithm in space linear in n and m. Between two equal items
+// deletions come before insertions.
+func editScript(n, m int, eq func(i, j int) bool) []editOp {
+	prefix := 0
+	for prefix < n && prefix < m && eq(prefix, prefix) {
+		prefix++
+	}
+	suffix := 0
+	for suffix < n-prefix && suffix < m-prefix && eq(n-1-suffix, m-1-suffix) {
+		suffix++
+	}
+
+	ops := make([]editOp, 0, n+m)
+	for range prefix {
+		ops = append(ops, opEqual)
+	}
+	ops = lcsScript(ops, prefix, n-suffix, prefix, m-suffix, eq)
+	for range suffix {
+		ops = append(ops, opEqual)
+	}
+
+	// Order the steps of every run of changes
+	for k := 0; k < len(ops); {
+		if ops[k] == opEqual {
+			k++
+			continue
+		}
+		end, deletes := k, 0
+		for ; end < len(ops) && ops[end] != opEqual; end++ {
+			if ops[end] == opDelete {
+				deletes++
+			}
+		}
+		for l := k; l < end; l++ {
+			ops[l] = opInsert
+			if l-k < deletes {
+				ops[l] = opDelete
+			}
+		}
+		k = end
+	}
+	return ops
+}
+
+// lcsScript appends the steps turning the items i0 to i1 of the first
+// sequence into the items j0 to j1 of the second to ops. It splits the first
+// range in half and the second where the longest common subsequences of the
+// halves add up to the longest one.
+func lcsScript(ops []editOp, i0, i1, j0, j1 int, eq func(i, j int) bool) []editOp {
+	switch {
+	case i0 == i1:
+		for range j1 - j0 {
+			ops = append(ops, opInsert)
+		}
+		return ops
+	case j0 == j1:
+		for range i1 - i0 {
+			ops = append(ops, opDelete)
+		}
+		return ops
+	case i1-i0 == 1:
+		for j := j0; j < j1; j++ {
+			if eq(i0, j) {
+				for range j - j0 {
+					ops = append(ops, opInsert)
+				}
+				ops = append(ops, opEqual)
+				for range j1 - j - 1 {
+					ops = append(ops, opInsert)
+				}
+				return ops
+			}
+		}
+		ops = append(ops, opDelete)
+		for range j1 - j0 {
+			ops = append(ops, opInsert)
+		}
+		return ops
+	}
+
+	mid := (i0 + i1) / 2
+	forward := lcsLengths(i0, mid, j0, j1, false, eq)
+	backward := lcsLengths(mid, i1, j0, j1, true, eq)
+	split, best := 0, -1
+	for k := range forward {
+		if total := forward[k] + backward[len(backward)-1-k]; total > best {
+			split, best = k, total
+		}
+	}
+	ops = lcsScript(ops, i0, mid, j0, j0+split, eq)
+	return lcsScript(ops, mid, i1, j0+split, j1, eq)
+}
+
+// lcsLengths returns the lengths of the longest common subsequences of the
+// items i0 to i1 of the first sequence and the first k items from j0 on of
+// the second, for every k. Reversed, both ranges are read backwards from
+// their ends instead.
+func lcsLengths(i0, i1, j0, j1 int, reversed bool, eq func(i, j int) bool) []int {
+	b := j1 - j0
+	prev, cur := make([]int, b+1), make([]int, b+1)
+	for i := 0; i < i1-i0; i++ {
+		for j := 0; j < b; j++ {
+			x, y := i0+i, j0+j
+			if reversed {
+				x, y = i1-1-i, j1-1-j
+			}
+			if eq(x, y) {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(prev[j+1], cur[j])
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev
+}
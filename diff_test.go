@@ -0,0 +1,143 @@
+package telegraph_test
+
+import (
+	"testing"
+
+	"github.com/smirnoffmg/telegraph"
+)
+
+func paragraph(children ...telegraph.Node) telegraph.NodeElement {
+	return telegraph.NodeElement{Tag: "p", Children: children}
+}
+
+func TestDiff(t *testing.T) {
+	link := func(href string) telegraph.NodeElement {
+		return telegraph.NodeElement{Tag: "a", Attrs: map[string]string{"href": href}, Children: []telegraph.Node{"link"}}
+	}
+
+	tests := []struct {
+		name string
+		old  []telegraph.Node
+		new  []telegraph.Node
+		want []string
+	}{
+		{
+			name: "Equal content",
+			old:  []telegraph.Node{paragraph("Hello")},
+			new:  []telegraph.Node{paragraph("Hello")},
+		},
+		{
+			name: "Text changed",
+			old:  []telegraph.Node{paragraph("Hello"), paragraph("World")},
+			new:  []telegraph.Node{paragraph("Hello"), paragraph("Telegraph")},
+			want: []string{`text changed at 1/0: "World" -> "Telegraph"`},
+		},
+		{
+			name: "Attribute changed",
+			old:  []telegraph.Node{paragraph(link("https://a.example"))},
+			new:  []telegraph.Node{paragraph(link("https://b.example"))},
+			want: []string{`attr href changed at 0/0: "https://a.example" -> "https://b.example"`},
+		},
+		{
+			name: "Node inserted",
+			old:  []telegraph.Node{paragraph("One"), paragraph("Three")},
+			new:  []telegraph.Node{paragraph("One"), paragraph("Two"), paragraph("Three")},
+			want: []string{`inserted at 1: <p>`},
+		},
+		{
+			name: "Node removed",
+			old:  []telegraph.Node{paragraph("One"), telegraph.NodeElement{Tag: "hr"}, paragraph("Two")},
+			new:  []telegraph.Node{paragraph("One"), paragraph("Two")},
+			want: []string{`removed at 1: <hr>`},
+		},
+		{
+			name: "Different tags are replaced",
+			old:  []telegraph.Node{telegraph.NodeElement{Tag: "h3", Children: []telegraph.Node{"Title"}}},
+			new:  []telegraph.Node{telegraph.NodeElement{Tag: "h4", Children: []telegraph.Node{"Title"}}},
+			want: []string{`removed at 0: <h3>`, `inserted at 0: <h4>`},
+		},
+		{
+			name: "Empty attributes equal missing ones",
+			old:  []telegraph.Node{paragraph(telegraph.NodeElement{Tag: "a", Attrs: map[string]string{"href": ""}, Children: []telegraph.Node{"link"}})},
+			new:  []telegraph.Node{paragraph(telegraph.NodeElement{Tag: "a", Children: []telegraph.Node{"link"}})},
+		},
+		{
+			name: "Decoded maps compare with elements",
+			old:  []telegraph.Node{map[string]interface{}{"tag": "p", "children": []interface{}{"Hello"}}},
+			new:  []telegraph.Node{paragraph("Hello")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := telegraph.Diff(tt.old, tt.new)
+			if len(changes) != len(tt.want) {
+				t.Fatalf("Expected %d changes, got %v", len(tt.want), changes)
+			}
+			for i, change := range changes {
+				if change.String() != tt.want[i] {
+					t.Errorf("Expected change '%s', got '%s'", tt.want[i], change)
+				}
+			}
+		})
+	}
+}
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		name string
+		a    []telegraph.Node
+		b    []telegraph.Node
+		want bool
+	}{
+		{
+			name: "Whitespace and empty attributes",
+			a:    []telegraph.Node{"\n", telegraph.NodeElement{Tag: "p", Attrs: map[string]string{}, Children: []telegraph.Node{" Hello  world "}}},
+			b:    []telegraph.Node{paragraph("Hello world")},
+			want: true,
+		},
+		{
+			name: "Adjacent text nodes",
+			a:    []telegraph.Node{paragraph("Hello ", "world")},
+			b:    []telegraph.Node{paragraph("Hello world")},
+			want: true,
+		},
+		{
+			name: "Different text",
+			a:    []telegraph.Node{paragraph("Hello")},
+			b:    []telegraph.Node{paragraph("World")},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := telegraph.Equal(tt.a, tt.b); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	old := []telegraph.Node{paragraph("One"), paragraph("Two"), paragraph("Three")}
+	new := []telegraph.Node{paragraph("One"), paragraph("2"), paragraph("Three")}
+
+	want := `--- old
++++ new
+@@ -1,6 +1,6 @@
+ <p>
+   "One"
+ <p>
+-  "Two"
++  "2"
+ <p>
+   "Three"
+`
+	if got := telegraph.UnifiedDiff(old, new); got != want {
+		t.Errorf("Expected diff:\n%s\ngot:\n%s", want, got)
+	}
+	if got := telegraph.UnifiedDiff(old, old); got != "" {
+		t.Errorf("Expected no diff, got:\n%s", got)
+	}
+}
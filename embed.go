@@ -0,0 +1,235 @@
+package telegraph
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// embedProvider recognizes links to a media service that Telegraph can embed
+type embedProvider struct {
+	// name is the provider segment of Telegraph's /embed/<name> path
+	name string
+	// canonical returns the link Telegraph expects for the media at u
+	canonical func(u *url.URL) (string, bool)
+}
+
+var embedProviders = []embedProvider{
+	{name: "youtube", canonical: youtubeURL},
+	{name: "vimeo", canonical: vimeoURL},
+	{name: "twitter", canonical: twitterURL},
+}
+
+var (
+	youtubeIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{6,}$`)
+	numericPattern   = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// EmbedURL returns the Telegraph embed path, such as /embed/youtube?url=...,
+// for a link to media of a supported provider (YouTube, Vimeo and Twitter).
+// It recognizes page links, player and iframe URLs, oEmbed endpoint URLs
+// carrying the media link in the url parameter, and existing embed paths.
+func EmbedURL(rawURL string) (string, bool) {
+	u, err := parseEmbedURL(rawURL)
+	if err != nil {
+		return "", false
+	}
+
+	if strings.HasPrefix(u.Path, "/embed/") && (u.Host == "" || isHost(u, "telegra.ph")) {
+		if target := u.Query().Get("url"); target != "" {
+			return EmbedURL(target)
+		}
+		return "", false
+	}
+
+	if base := path.Base(u.Path); base == "oembed" || strings.HasPrefix(base, "oembed.") {
+		if target := u.Query().Get("url"); target != "" {
+			return EmbedURL(target)
+		}
+		return "", false
+	}
+
+	for _, p := range embedProviders {
+		if canonical, ok := p.canonical(u); ok {
+			return "/embed/" + p.name + "?url=" + url.QueryEscape(canonical), true
+		}
+	}
+	return "", false
+}
+
+// EmbedNode returns a figure embedding the media at rawURL with an optional
+// caption, or false when the link is not embeddable
+func EmbedNode(rawURL, caption string) (Node, bool) {
+	src, ok := EmbedURL(rawURL)
+	if !ok {
+		return nil, false
+	}
+
+	figure := NodeElement{
+		Tag: "figure",
+		Children: []Node{
+			NodeElement{Tag: "iframe", Attrs: map[string]string{"src": src}},
+		},
+	}
+	if caption = strings.TrimSpace(caption); caption != "" {
+		figure.Children = append(figure.Children, NodeElement{Tag: "figcaption", Children: []Node{caption}})
+	}
+	return figure, true
+}
+
+// EmbedContent rewrites embeddable media in content into Telegraph's embed
+// format. Supported iframes get their src rewritten and are wrapped in a
+// figure unless they already are one's child, and paragraphs consisting of
+// nothing but a link to supported media become embed figures.
+func EmbedContent(content []Node) []Node {
+	out := make([]Node, 0, len(content))
+	for _, n := range content {
+		elem, ok := asElement(n)
+		if !ok {
+			out = append(out, n)
+			continue
+		}
+
+		switch elem.Tag {
+		case "iframe":
+			if figure, ok := EmbedNode(elem.Attrs["src"], ""); ok {
+				out = append(out, figure)
+				continue
+			}
+		case "p":
+			if link, ok := bareLink(elem); ok {
+				if figure, ok := EmbedNode(link, ""); ok {
+					out = append(out, figure)
+					continue
+				}
+			}
+		}
+		out = append(out, rewriteIframes(elem))
+	}
+	return out
+}
+
+// rewriteIframes rewrites the src of supported iframes nested in elem
+func rewriteIframes(elem NodeElement) NodeElement {
+	if elem.Tag == "iframe" {
+		if src, ok := EmbedURL(elem.Attrs["src"]); ok {
+			elem.Attrs = map[string]string{"src": src}
+		}
+		return elem
+	}
+
+	if len(elem.Children) == 0 {
+		return elem
+	}
+	children := make([]Node, len(elem.Children))
+	for i, child := range elem.Children {
+		if childElem, ok := asElement(child); ok {
+			children[i] = rewriteIframes(childElem)
+		} else {
+			children[i] = child
+		}
+	}
+	elem.Children = children
+	return elem
+}
+
+// bareLink returns the URL of a paragraph that holds nothing but a link,
+// either as plain text, as an iframe or as an a element showing its href
+func bareLink(p NodeElement) (string, bool) {
+	if len(p.Children) != 1 {
+		return "", false
+	}
+
+	child := p.Children[0]
+	if s, ok := child.(string); ok {
+		s = strings.TrimSpace(s)
+		return s, s != "" && !strings.ContainsAny(s, " \t\n")
+	}
+
+	elem, ok := asElement(child)
+	if !ok {
+		return "", false
+	}
+	switch elem.Tag {
+	case "iframe":
+		return elem.Attrs["src"], true
+	case "a":
+		href := elem.Attrs["href"]
+		text := strings.TrimSpace(nodeText(elem))
+		return href, href != "" && (text == href || text == "")
+	}
+	return "", false
+}
+
+// parseEmbedURL parses absolute and scheme-relative links
+func parseEmbedURL(rawURL string) (*url.URL, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if strings.HasPrefix(rawURL, "//") {
+		rawURL = "https:" + rawURL
+	}
+	return url.Parse(rawURL)
+}
+
+// isHost reports whether u points to domain or one of its subdomains
+func isHost(u *url.URL, domain string) bool {
+	host := strings.ToLower(u.Hostname())
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+func youtubeURL(u *url.URL) (string, bool) {
+	var id string
+	switch {
+	case isHost(u, "youtu.be"):
+		id = strings.Trim(u.Path, "/")
+	case isHost(u, "youtube.com"), isHost(u, "youtube-nocookie.com"):
+		segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+		switch {
+		case u.Path == "/watch":
+			id = u.Query().Get("v")
+		case len(segments) == 2 && (segments[0] == "embed" || segments[0] == "shorts" || segments[0] == "v" || segments[0] == "live"):
+			id = segments[1]
+		}
+	}
+
+	if !youtubeIDPattern.MatchString(id) {
+		return "", false
+	}
+	return "https://www.youtube.com/watch?v=" + id, true
+}
+
+func vimeoURL(u *url.URL) (string, bool) {
+	if !isHost(u, "vimeo.com") {
+		return "", false
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	id := segments[len(segments)-1]
+	if isHost(u, "player.vimeo.com") && (len(segments) != 2 || segments[0] != "video") {
+		return "", false
+	}
+	if !numericPattern.MatchString(id) {
+		return "", false
+	}
+	return "https://vimeo.com/" + id, true
+}
+
+func twitterURL(u *url.URL) (string, bool) {
+	if !isHost(u, "twitter.com") && !isHost(u, "x.com") {
+		return "", false
+	}
+
+	if isHost(u, "platform.twitter.com") {
+		id := u.Query().Get("id")
+		if !numericPattern.MatchString(id) {
+			return "", false
+		}
+		return "https://twitter.com/i/status/" + id, true
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(segments) < 3 || (segments[1] != "status" && segments[1] != "statuses") || !numericPattern.MatchString(segments[2]) {
+		return "", false
+	}
+	return "https://twitter.com/" + segments[0] + "/status/" + segments[2], true
+}
@@ -0,0 +1,84 @@
+package telegraph_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/smirnoffmg/telegraph"
+)
+
+func TestEmbedURL(t *testing.T) {
+	tests := []struct {
+		name   string
+		rawURL string
+		want   string
+		wantOk bool
+	}{
+		{"YouTube watch", "https://www.youtube.com/watch?v=dQw4w9WgXcQ&t=1", "/embed/youtube?url=https%3A%2F%2Fwww.youtube.com%2Fwatch%3Fv%3DdQw4w9WgXcQ", true},
+		{"YouTube iframe", "//www.youtube-nocookie.com/embed/dQw4w9WgXcQ", "/embed/youtube?url=https%3A%2F%2Fwww.youtube.com%2Fwatch%3Fv%3DdQw4w9WgXcQ", true},
+		{"YouTube short link", "https://youtu.be/dQw4w9WgXcQ", "/embed/youtube?url=https%3A%2F%2Fwww.youtube.com%2Fwatch%3Fv%3DdQw4w9WgXcQ", true},
+		{"Vimeo player", "https://player.vimeo.com/video/76979871", "/embed/vimeo?url=https%3A%2F%2Fvimeo.com%2F76979871", true},
+		{"Twitter status", "https://x.com/jack/status/20", "/embed/twitter?url=https%3A%2F%2Ftwitter.com%2Fjack%2Fstatus%2F20", true},
+		{
+			"oEmbed endpoint", "https://www.youtube.com/oembed?url=https%3A%2F%2Fyoutu.be%2FdQw4w9WgXcQ&format=json",
+			"/embed/youtube?url=https%3A%2F%2Fwww.youtube.com%2Fwatch%3Fv%3DdQw4w9WgXcQ", true,
+		},
+		{"Telegraph embed", "/embed/vimeo?url=https%3A%2F%2Fvimeo.com%2F76979871", "/embed/vimeo?url=https%3A%2F%2Fvimeo.com%2F76979871", true},
+		{"Unsupported", "https://example.com/video/1", "", false},
+		{"YouTube channel", "https://www.youtube.com/@someone", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := telegraph.EmbedURL(tt.rawURL)
+			if ok != tt.wantOk || got != tt.want {
+				t.Errorf("EmbedURL(%q) = %q, %v, want %q, %v", tt.rawURL, got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestHTMLToContentEmbeds(t *testing.T) {
+	htmlStr := `<iframe src="https://www.youtube.com/embed/dQw4w9WgXcQ"></iframe>
+<figure><iframe src="https://player.vimeo.com/video/76979871"></iframe><figcaption>Clip</figcaption></figure>
+<p><a href="https://twitter.com/jack/status/20">https://twitter.com/jack/status/20</a></p>
+<p>See <a href="https://youtu.be/dQw4w9WgXcQ">this</a></p>`
+	want := `[{"tag":"figure","children":[{"tag":"iframe","attrs":{"src":"/embed/youtube?url=https%3A%2F%2Fwww.youtube.com%2Fwatch%3Fv%3DdQw4w9WgXcQ"}}]},` +
+		`{"tag":"figure","children":[{"tag":"iframe","attrs":{"src":"/embed/vimeo?url=https%3A%2F%2Fvimeo.com%2F76979871"}},{"tag":"figcaption","children":["Clip"]}]},` +
+		`{"tag":"figure","children":[{"tag":"iframe","attrs":{"src":"/embed/twitter?url=https%3A%2F%2Ftwitter.com%2Fjack%2Fstatus%2F20"}}]},` +
+		`{"tag":"p","children":["See ",{"tag":"a","attrs":{"href":"https://youtu.be/dQw4w9WgXcQ"},"children":["this"]}]}]`
+
+	content, err := telegraph.HTMLToContent(htmlStr)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	got, err := json.Marshal(content)
+	if err != nil {
+		t.Fatalf("Failed to marshal content: %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, string(got))
+	}
+}
+
+func TestEmbedNode(t *testing.T) {
+	node, ok := telegraph.EmbedNode("https://vimeo.com/76979871", " A caption ")
+	if !ok {
+		t.Fatalf("Expected embeddable link")
+	}
+
+	got, err := json.Marshal(node)
+	if err != nil {
+		t.Fatalf("Failed to marshal node: %v", err)
+	}
+	want := `{"tag":"figure","children":[{"tag":"iframe","attrs":{"src":"/embed/vimeo?url=https%3A%2F%2Fvimeo.com%2F76979871"}},` +
+		`{"tag":"figcaption","children":["A caption"]}]}`
+	if string(got) != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, string(got))
+	}
+
+	if _, ok := telegraph.EmbedNode("https://example.com", ""); ok {
+		t.Errorf("Expected unsupported link to be rejected")
+	}
+}
@@ -1,6 +1,9 @@
 package telegraph
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 // Define package-specific errors
 var (
@@ -14,4 +17,35 @@ var (
 	ErrGetPageFailed           = errors.New("failed to get page")
 	ErrGetPageListFailed       = errors.New("failed to get page list")
 	ErrGetViewsFailed          = errors.New("failed to get views")
+	ErrContentTooLarge         = errors.New("content is too large")
+	ErrNoTableRenderer         = errors.New("no table renderer configured")
+	ErrTokenNotFound           = errors.New("token not found")
+	ErrInvalidTokenStore       = errors.New("invalid token store")
+	ErrAccountExists           = errors.New("account already exists")
+	ErrInvalidAccountField     = errors.New("invalid account field")
+	ErrUploadFailed            = errors.New("failed to upload file")
+	ErrNoDocumentID            = errors.New("document has no ID")
+	ErrStateNotSaved           = errors.New("publish state was not saved")
+	ErrEmptyContent            = errors.New("content is empty")
 )
+
+// APIError is an error reported by the Telegraph API, either in a response
+// with ok=false or in the payload of a response with an unexpected status
+type APIError struct {
+	Method     string // API method that failed, e.g. "getPage"
+	StatusCode int    // HTTP status code of the response
+	Message    string // error from the response, e.g. "PAGE_NOT_FOUND"
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("telegraph API error in %s", e.Method)
+	}
+	return fmt.Sprintf("telegraph API error in %s: %s", e.Method, e.Message)
+}
+
+// IsPageNotFound reports whether err is the API error for a missing page
+func IsPageNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Message == "PAGE_NOT_FOUND"
+}
@@ -0,0 +1,100 @@
+package watch
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// Events that mean a file in a watched directory was written or replaced
+const notifyMask = syscall.IN_CLOSE_WRITE | syscall.IN_MODIFY | syscall.IN_MOVED_TO | syscall.IN_CREATE
+
+// notify watches the directories of files with inotify and sends the paths
+// of changed files. Directories are watched rather than files so that files
+// replaced by renaming keep being watched. If reading the notifications
+// fails later on, fallback is called to watch the files another way.
+func notify(ctx context.Context, files []string, changes chan<- string, fallback func()) error {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return err
+	}
+	// A non-blocking descriptor makes reads interruptible by Close
+	f := os.NewFile(uintptr(fd), "inotify")
+
+	watched := make(map[string]struct{}, len(files))
+	dirs := make(map[int32]string)
+	for _, file := range files {
+		watched[file] = struct{}{}
+		dir := filepath.Dir(file)
+		wd, err := syscall.InotifyAddWatch(fd, dir, notifyMask)
+		if err != nil {
+			f.Close()
+			return err
+		}
+		dirs[int32(wd)] = dir
+	}
+
+	go func() {
+		<-ctx.Done()
+		f.Close()
+	}()
+	go forwardEvents(ctx, f, dirs, watched, changes, fallback)
+	return nil
+}
+
+// forwardEvents reads inotify events from r and sends the paths of the
+// watched files they name until ctx is done. If reading fails before that,
+// r is closed and fallback is called.
+func forwardEvents(ctx context.Context, r io.ReadCloser, dirs map[int32]string, watched map[string]struct{},
+	changes chan<- string, fallback func(),
+) {
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		n, err := r.Read(buf)
+		if err != nil {
+			if ctx.Err() == nil {
+				if !errors.Is(err, os.ErrClosed) {
+					r.Close()
+				}
+				fallback()
+			}
+			return
+		}
+		for _, path := range parseEvents(buf[:n], dirs) {
+			if _, ok := watched[path]; !ok {
+				continue
+			}
+			select {
+			case changes <- path:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// parseEvents returns the paths of the files named by the inotify events in buf
+func parseEvents(buf []byte, dirs map[int32]string) []string {
+	var paths []string
+	for len(buf) >= syscall.SizeofInotifyEvent {
+		wd := int32(binary.NativeEndian.Uint32(buf[0:4]))
+		nameLen := int(binary.NativeEndian.Uint32(buf[12:16]))
+		end := syscall.SizeofInotifyEvent + nameLen
+		if end > len(buf) {
+			break
+		}
+		name := string(buf[syscall.SizeofInotifyEvent:end])
+		for len(name) > 0 && name[len(name)-1] == 0 {
+			name = name[:len(name)-1]
+		}
+		if dir, ok := dirs[wd]; ok && name != "" {
+			paths = append(paths, filepath.Join(dir, name))
+		}
+		buf = buf[end:]
+	}
+	return paths
+}
@@ -0,0 +1,53 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// brokenReader fails every read
+type brokenReader struct{ closed bool }
+
+func (r *brokenReader) Read([]byte) (int, error) { return 0, errors.New("read failed") }
+
+func (r *brokenReader) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestForwardEventsFallback(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "draft.md")
+	if err := os.WriteFile(file, []byte("draft"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan string)
+	r := &brokenReader{}
+
+	// Changes are still reported by polling once notifications fail
+	go forwardEvents(ctx, r, nil, map[string]struct{}{file: {}}, changes, func() {
+		poll(ctx, []string{file}, 10*time.Millisecond, changes)
+	})
+	time.Sleep(50 * time.Millisecond)
+	if err := os.WriteFile(file, []byte("draft, longer"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case path := <-changes:
+		if path != file {
+			t.Errorf("Expected change of %s, got %s", file, path)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a change after notifications failed, got none")
+	}
+	if !r.closed {
+		t.Error("Expected the failed reader to be closed")
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package watch
+
+import "context"
+
+// notify is not supported, so files are polled
+func notify(context.Context, []string, chan<- string, func()) error {
+	return errUnsupported
+}
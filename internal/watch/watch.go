@@ -0,0 +1,162 @@
+// Package watch reports changes to files. It uses inotify on Linux and falls
+// back to polling the files elsewhere, when inotify is unavailable or when
+// it stops working.
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Defaults of Options
+const (
+	DefaultDebounce = 300 * time.Millisecond
+	DefaultInterval = 500 * time.Millisecond
+)
+
+// errUnsupported is returned by notify on systems without inotify
+var errUnsupported = errors.New("file system notifications are not supported")
+
+// Options controls how files are watched
+type Options struct {
+	// Debounce is how long a file must stay unchanged before its change is
+	// reported, DefaultDebounce when zero. Editors often write a file in
+	// several steps, which are reported as a single change.
+	Debounce time.Duration
+	// Interval is how often files are checked when polling, DefaultInterval
+	// when zero
+	Interval time.Duration
+	// Poll forces polling even where notifications are supported
+	Poll bool
+}
+
+// Watch watches the files at paths and sends the absolute path of a file on
+// the returned channel every time it changed and then stayed unchanged for
+// the debounce period. Files replaced by renaming, as many editors save
+// them, are followed. The channel is closed once ctx is done.
+func Watch(ctx context.Context, paths []string, opts Options) (<-chan string, error) {
+	if opts.Debounce <= 0 {
+		opts.Debounce = DefaultDebounce
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+
+	files := make([]string, len(paths))
+	for i, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := os.Stat(abs); err != nil {
+			return nil, fmt.Errorf("failed to watch %s: %w", path, err)
+		}
+		files[i] = abs
+	}
+
+	changes := make(chan string)
+	polling := func() { poll(ctx, files, opts.Interval, changes) }
+	if opts.Poll || notify(ctx, files, changes, polling) != nil {
+		go polling()
+	}
+
+	out := make(chan string)
+	go debounce(ctx, changes, opts.Debounce, out)
+	return out, nil
+}
+
+// debounce forwards paths from in to out once they were not received again
+// for delay
+func debounce(ctx context.Context, in <-chan string, delay time.Duration, out chan<- string) {
+	defer close(out)
+
+	pending := make(map[string]time.Time)
+	var fire <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case path := <-in:
+			pending[path] = time.Now().Add(delay)
+			fire = time.After(delay)
+		case now := <-fire:
+			var due []string
+			next := time.Duration(0)
+			for path, at := range pending {
+				if wait := at.Sub(now); wait > 0 {
+					if next == 0 || wait < next {
+						next = wait
+					}
+					continue
+				}
+				due = append(due, path)
+			}
+			sort.Strings(due)
+			for _, path := range due {
+				delete(pending, path)
+				select {
+				case out <- path:
+				case <-ctx.Done():
+					return
+				}
+			}
+			fire = nil
+			if next > 0 {
+				fire = time.After(next)
+			}
+		}
+	}
+}
+
+// fileState is what polling compares to detect changes
+type fileState struct {
+	modTime time.Time
+	size    int64
+	exists  bool
+}
+
+func stat(path string) fileState {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{modTime: info.ModTime(), size: info.Size(), exists: true}
+}
+
+// poll checks the files every interval and sends the paths of changed ones
+func poll(ctx context.Context, files []string, interval time.Duration, changes chan<- string) {
+	states := make(map[string]fileState, len(files))
+	for _, file := range files {
+		states[file] = stat(file)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for _, file := range files {
+			current := stat(file)
+			if current == states[file] {
+				continue
+			}
+			states[file] = current
+			if !current.exists {
+				continue
+			}
+			select {
+			case changes <- file:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	tests := []struct {
+		name string
+		poll bool
+	}{
+		{"Notifications", false},
+		{"Polling", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			file := filepath.Join(dir, "draft.md")
+			other := filepath.Join(dir, "other.md")
+			for _, path := range []string{file, other} {
+				if err := os.WriteFile(path, []byte("draft"), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			changes, err := Watch(ctx, []string{file}, Options{Debounce: 100 * time.Millisecond, Interval: 20 * time.Millisecond, Poll: tt.poll})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			// Several quick writes, an unwatched file and a save by renaming
+			// are reported as one change
+			for i := 0; i < 3; i++ {
+				if err := os.WriteFile(file, []byte("draft "+string(rune('a'+i))), 0o600); err != nil {
+					t.Fatal(err)
+				}
+				time.Sleep(30 * time.Millisecond)
+			}
+			if err := os.WriteFile(other, []byte("other"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			tmp := filepath.Join(dir, ".draft.md.swp")
+			if err := os.WriteFile(tmp, []byte("draft, final version"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Rename(tmp, file); err != nil {
+				t.Fatal(err)
+			}
+
+			select {
+			case path := <-changes:
+				if path != file {
+					t.Errorf("Expected change of %s, got %s", file, path)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("Expected a change, got none")
+			}
+
+			select {
+			case path := <-changes:
+				t.Errorf("Expected a single change, got another one of %s", path)
+			case <-time.After(300 * time.Millisecond):
+			}
+
+			cancel()
+			for range changes {
+			}
+		})
+	}
+}
+
+func TestWatchMissingFile(t *testing.T) {
+	if _, err := Watch(context.Background(), []string{filepath.Join(t.TempDir(), "missing.md")}, Options{}); err == nil {
+		t.Error("Expected error for a missing file, got nil")
+	}
+}
@@ -0,0 +1,30 @@
+package telegraph
+
+// RewriteURLs returns a copy of content with the href and src attributes of
+// its elements replaced by the result of rewrite, which receives the tag of
+// the element, the name of the attribute and its value. Returning the value
+// unchanged keeps the attribute as it is.
+func RewriteURLs(content []Node, rewrite func(tag, attr, value string) string) []Node {
+	out := make([]Node, 0, len(content))
+	for _, n := range content {
+		elem, ok := asElement(n)
+		if !ok {
+			out = append(out, n)
+			continue
+		}
+
+		if len(elem.Attrs) > 0 {
+			attrs := make(map[string]string, len(elem.Attrs))
+			for key, value := range elem.Attrs {
+				if key == "href" || key == "src" {
+					value = rewrite(elem.Tag, key, value)
+				}
+				attrs[key] = value
+			}
+			elem.Attrs = attrs
+		}
+		elem.Children = RewriteURLs(elem.Children, rewrite)
+		out = append(out, elem)
+	}
+	return out
+}
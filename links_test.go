@@ -0,0 +1,44 @@
+package telegraph_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/smirnoffmg/telegraph"
+)
+
+func TestRewriteURLs(t *testing.T) {
+	content := []telegraph.Node{
+		telegraph.NodeElement{Tag: "p", Children: []telegraph.Node{
+			telegraph.NodeElement{Tag: "a", Attrs: map[string]string{"href": "other.md"}, Children: []telegraph.Node{"Other"}},
+			telegraph.NodeElement{Tag: "a", Attrs: map[string]string{"href": "https://example.com"}, Children: []telegraph.Node{"Example"}},
+		}},
+		map[string]interface{}{"tag": "img", "attrs": map[string]interface{}{"src": "/file/cat.png"}},
+	}
+
+	got := telegraph.RewriteURLs(content, func(tag, attr, value string) string {
+		switch {
+		case tag == "a" && strings.HasSuffix(value, ".md"):
+			return "https://telegra.ph/Other-10-19"
+		case attr == "src":
+			return "assets/cat.png"
+		}
+		return value
+	})
+
+	want := `[{"tag":"p","children":[{"tag":"a","attrs":{"href":"https://telegra.ph/Other-10-19"},"children":["Other"]},` +
+		`{"tag":"a","attrs":{"href":"https://example.com"},"children":["Example"]}]},{"tag":"img","attrs":{"src":"assets/cat.png"}}]`
+	data, err := json.Marshal(got)
+	if err != nil {
+		t.Fatalf("Failed to marshal content: %v", err)
+	}
+	if string(data) != want {
+		t.Errorf("Expected %s, got %s", want, data)
+	}
+
+	// The original content is left untouched
+	if href := content[0].(telegraph.NodeElement).Children[0].(telegraph.NodeElement).Attrs["href"]; href != "other.md" {
+		t.Errorf("Expected original href 'other.md', got '%s'", href)
+	}
+}
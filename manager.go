@@ -0,0 +1,180 @@
+package telegraph
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// AccountManager manages named Telegraph accounts whose access tokens are
+// kept in a TokenStore and hands out clients scoped to a single account
+type AccountManager struct {
+	client   *Client
+	store    TokenStore
+	mu       sync.Mutex
+	accounts map[string]*AccountClient
+	creating map[string]struct{} // names of accounts being created
+}
+
+// TokenRotationError is returned by RotateToken when the token was revoked
+// but the new one could not be saved. The old token no longer works, so the
+// new one is in use by the account's client and must be persisted by hand.
+type TokenRotationError struct {
+	Name    string
+	Account *Account // account with the new access token
+	Err     error
+}
+
+func (e *TokenRotationError) Error() string {
+	return fmt.Sprintf("token of %s was revoked but the new token was not saved: %v", e.Name, e.Err)
+}
+
+func (e *TokenRotationError) Unwrap() error {
+	return e.Err
+}
+
+// AccountCreationError is returned by Create when the account was created
+// but its token could not be saved. The account exists on Telegraph and its
+// token is only known to the returned client, so it must be persisted by hand.
+type AccountCreationError struct {
+	Name    string
+	Account *Account // the new account with its access token
+	Err     error
+}
+
+func (e *AccountCreationError) Error() string {
+	return fmt.Sprintf("account %s was created but its token was not saved: %v", e.Name, e.Err)
+}
+
+func (e *AccountCreationError) Unwrap() error {
+	return e.Err
+}
+
+// NewAccountManager creates an account manager using client for API requests
+// and store to persist access tokens
+func NewAccountManager(client *Client, store TokenStore) *AccountManager {
+	return &AccountManager{
+		client:   client,
+		store:    store,
+		accounts: make(map[string]*AccountClient),
+		creating: make(map[string]struct{}),
+	}
+}
+
+// Create creates a new Telegraph account, stores its token under name and
+// returns a client for it. It fails with ErrAccountExists if name is taken.
+//
+// If saving the token fails, the client is returned anyway together with a
+// *AccountCreationError carrying the account and its token.
+func (m *AccountManager) Create(name, shortName, authorName, authorURL string) (*AccountClient, error) {
+	if err := m.reserve(name); err != nil {
+		return nil, err
+	}
+
+	// The name is reserved, so the request is made without holding the lock
+	account, err := m.client.CreateAccount(shortName, authorName, authorURL)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.creating, name)
+	if err != nil {
+		return nil, err
+	}
+
+	ac := &AccountClient{client: m.client, manager: m, name: name, token: account.AccessToken}
+	m.accounts[name] = ac
+	if err := m.store.Save(name, account.AccessToken); err != nil {
+		return ac, &AccountCreationError{Name: name, Account: account, Err: err}
+	}
+	return ac, nil
+}
+
+// reserve marks name as being created unless an account uses it already
+func (m *AccountManager) reserve(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.accounts[name]; ok {
+		return fmt.Errorf("%w: %s", ErrAccountExists, name)
+	}
+	if _, ok := m.creating[name]; ok {
+		return fmt.Errorf("%w: %s", ErrAccountExists, name)
+	}
+	if _, err := m.store.Load(name); err == nil {
+		return fmt.Errorf("%w: %s", ErrAccountExists, name)
+	} else if !errors.Is(err, ErrTokenNotFound) {
+		return err
+	}
+	m.creating[name] = struct{}{}
+	return nil
+}
+
+// Get returns the client for the account stored under name, loading its
+// token from the store on first use. Repeated calls return the same client.
+func (m *AccountManager) Get(name string) (*AccountClient, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if ac, ok := m.accounts[name]; ok {
+		return ac, nil
+	}
+
+	token, err := m.store.Load(name)
+	if err != nil {
+		return nil, err
+	}
+
+	ac := &AccountClient{client: m.client, manager: m, name: name, token: token}
+	m.accounts[name] = ac
+	return ac, nil
+}
+
+// Names returns the names of all stored accounts
+func (m *AccountManager) Names() ([]string, error) {
+	return m.store.Names()
+}
+
+// Remove forgets the account stored under name. The account itself stays
+// on Telegraph; revoke its token first to lock it.
+func (m *AccountManager) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.store.Delete(name); err != nil {
+		return err
+	}
+	delete(m.accounts, name)
+	return nil
+}
+
+// RotateToken revokes the access token of the account stored under name and
+// switches to a new one. The new token is saved to the store and swapped into
+// the account's client; calls made through the client while the rotation is
+// in progress wait for it and use the new token. The returned account holds
+// the new token and a fresh AuthURL for logging in from a browser.
+//
+// If revoking fails nothing changes. If saving fails the new token is still
+// used by the client and a *TokenRotationError carrying it is returned.
+func (m *AccountManager) RotateToken(name string) (*Account, error) {
+	ac, err := m.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return m.rotate(ac)
+}
+
+// rotate revokes the token of ac, a client handed out by m, and saves the new one
+func (m *AccountManager) rotate(ac *AccountClient) (*Account, error) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	account, err := ac.revokeLocked()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.store.Save(ac.name, account.AccessToken); err != nil {
+		return account, &TokenRotationError{Name: ac.name, Account: account, Err: err}
+	}
+	return account, nil
+}
@@ -0,0 +1,261 @@
+package telegraph_test
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/smirnoffmg/telegraph"
+)
+
+func TestFileTokenStore(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	encrypted, err := telegraph.NewEncryptedFileTokenStore(filepath.Join(t.TempDir(), "tokens.bin"), key)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	stores := map[string]*telegraph.FileTokenStore{
+		"Plain":     telegraph.NewFileTokenStore(filepath.Join(t.TempDir(), "tokens.json")),
+		"Encrypted": encrypted,
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			if _, err := store.Load("blog"); !errors.Is(err, telegraph.ErrTokenNotFound) {
+				t.Fatalf("Expected ErrTokenNotFound, got %v", err)
+			}
+
+			if err := store.Save("blog", "token-1"); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if err := store.Save("news", "token-2"); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			token, err := store.Load("blog")
+			if err != nil || token != "token-1" {
+				t.Errorf("Expected token-1, got '%s' (%v)", token, err)
+			}
+
+			names, err := store.Names()
+			if err != nil || strings.Join(names, ",") != "blog,news" {
+				t.Errorf("Expected names blog,news, got %v (%v)", names, err)
+			}
+
+			if err := store.Delete("blog"); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if _, err := store.Load("blog"); !errors.Is(err, telegraph.ErrTokenNotFound) {
+				t.Errorf("Expected ErrTokenNotFound after delete, got %v", err)
+			}
+		})
+	}
+}
+
+func TestEncryptedFileTokenStoreWrongKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.bin")
+	store, err := telegraph.NewEncryptedFileTokenStore(path, []byte("0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := store.Save("blog", "secret-token"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read store: %v", err)
+	}
+	if strings.Contains(string(data), "secret-token") {
+		t.Errorf("Expected token to be encrypted on disk")
+	}
+
+	other, err := telegraph.NewEncryptedFileTokenStore(path, []byte("fedcba9876543210"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := other.Load("blog"); !errors.Is(err, telegraph.ErrInvalidTokenStore) {
+		t.Errorf("Expected ErrInvalidTokenStore, got %v", err)
+	}
+}
+
+func TestAccountManager(t *testing.T) {
+	server := mockServer(testAccountResponse, http.StatusOK)
+	defer server.Close()
+
+	client := telegraph.NewClient(server.Client())
+	client.SetBaseURL(server.URL + "/")
+
+	store := telegraph.NewFileTokenStore(filepath.Join(t.TempDir(), "tokens.json"))
+	manager := telegraph.NewAccountManager(client, store)
+
+	ac, err := manager.Create("blog", shortName, authorName, authorURL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if ac.Name() != "blog" || ac.Token() != accessToken {
+		t.Errorf("Expected blog with token '%s', got %s with '%s'", accessToken, ac.Name(), ac.Token())
+	}
+
+	if _, err := manager.Create("blog", shortName, authorName, authorURL); !errors.Is(err, telegraph.ErrAccountExists) {
+		t.Errorf("Expected ErrAccountExists, got %v", err)
+	}
+
+	// A new manager loads the account from the store
+	loaded, err := telegraph.NewAccountManager(client, store).Get("blog")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if loaded.Token() != accessToken {
+		t.Errorf("Expected token '%s', got '%s'", accessToken, loaded.Token())
+	}
+
+	account, err := loaded.GetAccountInfo([]string{"short_name"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if account.ShortName != shortName {
+		t.Errorf("Expected ShortName to be '%s', got '%s'", shortName, account.ShortName)
+	}
+
+	if err := manager.Remove("blog"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := manager.Get("blog"); !errors.Is(err, telegraph.ErrTokenNotFound) {
+		t.Errorf("Expected ErrTokenNotFound, got %v", err)
+	}
+}
+
+type failingTokenStore struct {
+	telegraph.TokenStore
+}
+
+func (failingTokenStore) Save(string, string) error {
+	return errors.New("disk full")
+}
+
+func TestAccountManagerRotateToken(t *testing.T) {
+	const newToken = "654321"
+	server := mockServer(`{"ok":true,"result":{"access_token":"`+newToken+`","auth_url":"https://edit.telegra.ph/auth/abc"}}`, http.StatusOK)
+	defer server.Close()
+
+	client := telegraph.NewClient(server.Client())
+	client.SetBaseURL(server.URL + "/")
+
+	store := telegraph.NewFileTokenStore(filepath.Join(t.TempDir(), "tokens.json"))
+	if err := store.Save("blog", accessToken); err != nil {
+		t.Fatalf("Failed to save token: %v", err)
+	}
+	manager := telegraph.NewAccountManager(client, store)
+	ac, err := manager.Get("blog")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if token := ac.Token(); token != accessToken && token != newToken {
+				t.Errorf("Unexpected token '%s'", token)
+			}
+		}()
+	}
+
+	account, err := manager.RotateToken("blog")
+	wg.Wait()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if account.AuthURL != "https://edit.telegra.ph/auth/abc" {
+		t.Errorf("Expected auth URL, got '%s'", account.AuthURL)
+	}
+	if ac.Token() != newToken {
+		t.Errorf("Expected client to use token '%s', got '%s'", newToken, ac.Token())
+	}
+	if token, _ := store.Load("blog"); token != newToken {
+		t.Errorf("Expected store to hold token '%s', got '%s'", newToken, token)
+	}
+
+	// The new token stays in use when it cannot be saved
+	manager = telegraph.NewAccountManager(client, failingTokenStore{store})
+	ac, err = manager.Get("blog")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	_, err = manager.RotateToken("blog")
+	var rotationErr *telegraph.TokenRotationError
+	if !errors.As(err, &rotationErr) {
+		t.Fatalf("Expected TokenRotationError, got %v", err)
+	}
+	if rotationErr.Account.AccessToken != newToken || ac.Token() != newToken {
+		t.Errorf("Expected new token to be reported and in use")
+	}
+
+	// Nothing changes when revoking fails
+	failing := mockServer(testErrorResponse, http.StatusOK)
+	defer failing.Close()
+	client.SetBaseURL(failing.URL + "/")
+	if _, err := telegraph.NewAccountManager(client, store).RotateToken("blog"); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+	if token, _ := store.Load("blog"); token != newToken {
+		t.Errorf("Expected stored token to be unchanged, got '%s'", token)
+	}
+}
+
+func TestAccountManagerCreateStoreFailure(t *testing.T) {
+	server := mockServer(testAccountResponse, http.StatusOK)
+	defer server.Close()
+
+	client := telegraph.NewClient(server.Client())
+	client.SetBaseURL(server.URL + "/")
+
+	store := telegraph.NewFileTokenStore(filepath.Join(t.TempDir(), "tokens.json"))
+	manager := telegraph.NewAccountManager(client, failingTokenStore{store})
+
+	// The token of the new account is not lost when it cannot be saved
+	ac, err := manager.Create("blog", shortName, authorName, authorURL)
+	var creationErr *telegraph.AccountCreationError
+	if !errors.As(err, &creationErr) {
+		t.Fatalf("Expected AccountCreationError, got %v", err)
+	}
+	if creationErr.Account.AccessToken != accessToken || ac == nil || ac.Token() != accessToken {
+		t.Errorf("Expected the new token to be reported and in use, got %+v", creationErr.Account)
+	}
+	if _, err := manager.Create("blog", shortName, authorName, authorURL); !errors.Is(err, telegraph.ErrAccountExists) {
+		t.Errorf("Expected ErrAccountExists, got %v", err)
+	}
+}
+
+func TestAccountManagerRevokeAccessToken(t *testing.T) {
+	const newToken = "654321"
+	server := mockServer(`{"ok":true,"result":{"access_token":"`+newToken+`"}}`, http.StatusOK)
+	defer server.Close()
+
+	client := telegraph.NewClient(server.Client())
+	client.SetBaseURL(server.URL + "/")
+
+	store := telegraph.NewFileTokenStore(filepath.Join(t.TempDir(), "tokens.json"))
+	if err := store.Save("blog", accessToken); err != nil {
+		t.Fatalf("Failed to save token: %v", err)
+	}
+	ac, err := telegraph.NewAccountManager(client, store).Get("blog")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Revoking through a client of the manager saves the new token
+	if _, err := ac.RevokeAccessToken(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if token, _ := store.Load("blog"); token != newToken || ac.Token() != newToken {
+		t.Errorf("Expected token '%s' in the store and the client, got '%s' and '%s'", newToken, token, ac.Token())
+	}
+}
@@ -0,0 +1,839 @@
+package telegraph
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Width of a tab and of the indentation of code blocks in Markdown
+const mdTabWidth = 4
+
+// Markdown block syntax
+var (
+	mdHeading = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	mdSetext  = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	mdRule    = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	mdFence   = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})")
+	mdQuote   = regexp.MustCompile(`^ {0,3}> ?`)
+	mdItem    = regexp.MustCompile(`^( {0,3})([-*+]|\d{1,9}[.)])([ \t]+|$)`)
+	mdAuto    = regexp.MustCompile(`^<((?:https?|ftp)://[^\s<>]+|mailto:[^\s<>]+)>`)
+)
+
+// ConvertMarkdown reads Markdown from r and transforms it to a slice of
+// telegraph.Nodes. It supports ATX and setext headings (levels 1 and 2
+// become h3, deeper levels h4), paragraphs, block quotes, nested lists,
+// fenced and indented code, horizontal rules, emphasis, strong emphasis,
+// strikethrough, code spans, links, images and autolinks. An image alone in
+// a paragraph becomes a figure captioned with its alt text. Raw HTML, inline
+// or in blocks, is not interpreted: it is kept as literal text, tags
+// included, so convert such documents with ConvertHTML instead.
+// Like ConvertHTML, the result is normalized and links to supported media
+// are turned into embeds.
+func ConvertMarkdown(r io.Reader) ([]Node, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Markdown: %w", err)
+	}
+
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	content := markdownBlocks(strings.Split(text, "\n"))
+	return EmbedContent(NormalizeContent(content)), nil
+}
+
+// MarkdownToContent transforms Markdown string to a slice of telegraph.Nodes.
+// See ConvertMarkdown for details.
+func MarkdownToContent(markdown string) ([]Node, error) {
+	return ConvertMarkdown(strings.NewReader(markdown))
+}
+
+// ContentToMarkdown renders content as Markdown that ConvertMarkdown turns
+// back into the same kind of content. Headings h3 and h4 become levels 2 and
+// 3, figures become images with their caption as alt text and embeds become
+// bare links to the embedded media. Underline has no Markdown syntax and is
+// rendered as plain text.
+func ContentToMarkdown(content []Node) string {
+	var blocks []string
+	for _, n := range content {
+		if block := mdRenderBlock(n); block != "" {
+			blocks = append(blocks, block)
+		}
+	}
+	if len(blocks) == 0 {
+		return ""
+	}
+	return strings.Join(blocks, "\n\n") + "\n"
+}
+
+// markdownBlocks converts lines of Markdown to block nodes
+func markdownBlocks(lines []string) []Node {
+	var out []Node
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		var node Node
+		switch {
+		case strings.TrimSpace(line) == "":
+			i++
+			continue
+		case mdFence.MatchString(line):
+			node, i = mdFencedCode(lines, i)
+		case mdHeading.MatchString(line):
+			m := mdHeading.FindStringSubmatch(line)
+			node = mdHeadingNode(len(m[1]), m[2])
+			i++
+		case mdRule.MatchString(line):
+			node = NodeElement{Tag: "hr"}
+			i++
+		case mdQuote.MatchString(line):
+			node, i = mdBlockquote(lines, i)
+		case mdItem.MatchString(line):
+			node, i = mdList(lines, i)
+		case indentWidth(line) >= mdTabWidth:
+			node, i = mdIndentedCode(lines, i)
+		default:
+			node, i = mdParagraph(lines, i)
+		}
+		out = append(out, node)
+	}
+	return out
+}
+
+// mdStartsBlock reports whether line starts a block that interrupts a paragraph
+func mdStartsBlock(line string) bool {
+	return strings.TrimSpace(line) == "" || mdFence.MatchString(line) || mdHeading.MatchString(line) ||
+		mdRule.MatchString(line) || mdQuote.MatchString(line) || mdItem.MatchString(line)
+}
+
+// mdHeadingNode returns the Telegraph heading for a heading of the given level
+func mdHeadingNode(level int, text string) Node {
+	tag := "h4"
+	if level <= 2 {
+		tag = "h3"
+	}
+	return NodeElement{Tag: tag, Children: markdownInline(strings.TrimSpace(text))}
+}
+
+// mdParagraph converts the paragraph starting at lines[i], which may turn
+// out to be a setext heading
+func mdParagraph(lines []string, i int) (Node, int) {
+	var text []string
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if len(text) > 0 {
+			if m := mdSetext.FindStringSubmatch(line); m != nil {
+				level := 1
+				if m[1][0] == '-' {
+					level = 2
+				}
+				return mdHeadingNode(level, strings.Join(text, "\n")), i + 1
+			}
+			if mdStartsBlock(line) {
+				break
+			}
+		}
+		text = append(text, strings.TrimLeft(line, " \t"))
+	}
+
+	joined := strings.TrimRight(strings.Join(text, "\n"), " \t")
+	if figure, ok := mdFigure(joined); ok {
+		return figure, i
+	}
+	return NodeElement{Tag: "p", Children: markdownInline(joined)}, i
+}
+
+// mdFigure returns a figure for a paragraph made of a single image
+func mdFigure(text string) (Node, bool) {
+	if !strings.HasPrefix(text, "![") {
+		return nil, false
+	}
+	alt, src, n, ok := mdLink(text[1:])
+	if !ok || n+1 != len(text) {
+		return nil, false
+	}
+
+	figure := NodeElement{Tag: "figure", Children: []Node{
+		NodeElement{Tag: "img", Attrs: map[string]string{"src": src}},
+	}}
+	if alt != "" {
+		figure.Children = append(figure.Children, NodeElement{Tag: "figcaption", Children: markdownInline(alt)})
+	}
+	return figure, true
+}
+
+// mdFencedCode converts the fenced code block starting at lines[i]
+func mdFencedCode(lines []string, i int) (Node, int) {
+	m := mdFence.FindStringSubmatch(lines[i])
+	indent, fence := len(m[1]), m[2]
+
+	var code []string
+	for i++; i < len(lines); i++ {
+		closing := strings.TrimLeft(lines[i], " ")
+		if len(lines[i])-len(closing) < mdTabWidth && strings.HasPrefix(closing, fence) &&
+			strings.Trim(closing, fence[:1]+" \t") == "" {
+			i++
+			break
+		}
+		code = append(code, stripIndent(lines[i], indent))
+	}
+	return mdCode(code), i
+}
+
+// mdIndentedCode converts the indented code block starting at lines[i]
+func mdIndentedCode(lines []string, i int) (Node, int) {
+	var code []string
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) != "" && indentWidth(line) < mdTabWidth {
+			break
+		}
+		code = append(code, stripIndent(line, mdTabWidth))
+	}
+	// Blank lines after the block belong to the document
+	for len(code) > 0 && strings.TrimSpace(code[len(code)-1]) == "" {
+		code = code[:len(code)-1]
+	}
+	return mdCode(code), i
+}
+
+// mdCode returns a pre element holding lines of code
+func mdCode(code []string) Node {
+	return NodeElement{Tag: "pre", Children: []Node{strings.Join(code, "\n")}}
+}
+
+// mdBlockquote converts the block quote starting at lines[i]. Telegraph
+// quotes hold inline content, so paragraphs are separated by line breaks.
+func mdBlockquote(lines []string, i int) (Node, int) {
+	var inner []string
+	for ; i < len(lines); i++ {
+		loc := mdQuote.FindStringIndex(lines[i])
+		if loc == nil {
+			// Lazy continuation of a paragraph in the quote
+			if len(inner) == 0 || strings.TrimSpace(inner[len(inner)-1]) == "" || mdStartsBlock(lines[i]) {
+				break
+			}
+			inner = append(inner, lines[i])
+			continue
+		}
+		inner = append(inner, lines[i][loc[1]:])
+	}
+	return NodeElement{Tag: "blockquote", Children: inlineBlocks(markdownBlocks(inner))}, i
+}
+
+// mdList converts the list starting at lines[i]
+func mdList(lines []string, i int) (Node, int) {
+	ordered := isOrderedMarker(mdItem.FindStringSubmatch(lines[i])[2])
+	list := NodeElement{Tag: "ul"}
+	if ordered {
+		list.Tag = "ol"
+	}
+
+	for i < len(lines) {
+		m := mdItem.FindStringSubmatch(lines[i])
+		if m == nil || isOrderedMarker(m[2]) != ordered {
+			break
+		}
+
+		// Content of the item is indented to the column after the marker
+		width := len(m[1]) + len(m[2]) + 1
+		if spaces := indentWidth(m[3]); spaces > 0 && spaces <= mdTabWidth {
+			width = len(m[1]) + len(m[2]) + spaces
+		}
+		item := []string{lines[i][len(m[0]):]}
+
+		for i++; i < len(lines); i++ {
+			line := lines[i]
+			if strings.TrimSpace(line) == "" {
+				next := nextNonBlank(lines, i)
+				if next == len(lines) || indentWidth(lines[next]) < width {
+					break
+				}
+				item = append(item, "")
+				continue
+			}
+			if indentWidth(line) >= width {
+				item = append(item, stripIndent(line, width))
+				continue
+			}
+			if mdStartsBlock(line) {
+				break
+			}
+			// Lazy continuation of the item's paragraph
+			item = append(item, strings.TrimLeft(line, " \t"))
+		}
+		list.Children = append(list.Children, NodeElement{Tag: "li", Children: inlineBlocks(markdownBlocks(item))})
+
+		// Blank lines may separate items of the same list
+		if next := nextNonBlank(lines, i); next < len(lines) {
+			if m := mdItem.FindStringSubmatch(lines[next]); m != nil && isOrderedMarker(m[2]) == ordered {
+				i = next
+			}
+		}
+	}
+	return list, i
+}
+
+// inlineBlocks replaces paragraphs in blocks with their children, separating
+// consecutive paragraphs by blank lines made of line breaks
+func inlineBlocks(blocks []Node) []Node {
+	var out []Node
+	prevParagraph := false
+	for _, block := range blocks {
+		elem, ok := asElement(block)
+		if !ok || elem.Tag != "p" {
+			out = append(out, block)
+			prevParagraph = false
+			continue
+		}
+		if prevParagraph {
+			out = append(out, NodeElement{Tag: "br"}, NodeElement{Tag: "br"})
+		}
+		out = append(out, elem.Children...)
+		prevParagraph = true
+	}
+	return out
+}
+
+// markdownInline converts inline Markdown to nodes
+func markdownInline(s string) []Node {
+	var out []Node
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			out = append(out, html.UnescapeString(text.String()))
+			text.Reset()
+		}
+	}
+	emit := func(n Node) {
+		flush()
+		out = append(out, n)
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && s[i+1] == '\n':
+			emit(NodeElement{Tag: "br"})
+			i += 2
+			continue
+		case c == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]):
+			text.WriteString(html.EscapeString(s[i+1 : i+2]))
+			i += 2
+			continue
+		case c == '\n':
+			// Two trailing spaces make a hard line break
+			if t := text.String(); strings.HasSuffix(t, "  ") {
+				text.Reset()
+				text.WriteString(strings.TrimRight(t, " "))
+				emit(NodeElement{Tag: "br"})
+			} else {
+				text.WriteByte(c)
+			}
+			i++
+			continue
+		case c == '`':
+			n := runLength(s, i)
+			if end := strings.Index(s[i+n:], s[i:i+n]); end >= 0 {
+				emit(NodeElement{Tag: "code", Children: []Node{mdCodeSpan(s[i+n : i+n+end])}})
+				i += n + end + n
+				continue
+			}
+			text.WriteString(s[i : i+n])
+			i += n
+			continue
+		case c == '!' && strings.HasPrefix(s[i+1:], "["):
+			if _, src, n, ok := mdLink(s[i+1:]); ok {
+				emit(NodeElement{Tag: "img", Attrs: map[string]string{"src": src}})
+				i += 1 + n
+				continue
+			}
+		case c == '[':
+			if label, href, n, ok := mdLink(s[i:]); ok {
+				emit(NodeElement{Tag: "a", Attrs: map[string]string{"href": href}, Children: markdownInline(label)})
+				i += n
+				continue
+			}
+		case c == '<':
+			if m := mdAuto.FindStringSubmatch(s[i:]); m != nil {
+				emit(NodeElement{Tag: "a", Attrs: map[string]string{"href": m[1]}, Children: []Node{m[1]}})
+				i += len(m[0])
+				continue
+			}
+		case c == '*' || c == '_' || c == '~':
+			if tag, inner, n, ok := mdEmphasis(s, i); ok {
+				emit(NodeElement{Tag: tag, Children: markdownInline(inner)})
+				i += n
+				continue
+			}
+			n := runLength(s, i)
+			text.WriteString(s[i : i+n])
+			i += n
+			continue
+		}
+		text.WriteByte(c)
+		i++
+	}
+	flush()
+	return out
+}
+
+// mdEmphasis parses emphasis, strong emphasis or strikethrough starting at
+// s[i] and returns its tag, inner text and length
+func mdEmphasis(s string, i int) (string, string, int, bool) {
+	c := s[i]
+	n := min(runLength(s, i), 2)
+	tag := map[int]string{1: "em", 2: "strong"}[n]
+	if c == '~' {
+		if n != 2 {
+			return "", "", 0, false
+		}
+		tag = "s"
+	}
+
+	// Underscores inside words are not emphasis
+	if c == '_' && i > 0 && isWordByte(s[i-1]) {
+		return "", "", 0, false
+	}
+	start := i + n
+	if start >= len(s) || s[start] == ' ' || s[start] == '\n' {
+		return "", "", 0, false
+	}
+
+	delim := s[i:start]
+	for k := start + 1; k+n <= len(s); k++ {
+		if s[k:k+n] != delim || s[k-1] == ' ' || s[k-1] == '\n' {
+			continue
+		}
+		if n == 1 && (s[k-1] == c || (k+1 < len(s) && s[k+1] == c)) {
+			continue
+		}
+		// Closing runs longer than the delimiter close at their end
+		for k+n < len(s) && s[k+n] == c {
+			k++
+		}
+		if c == '_' && k+n < len(s) && isWordByte(s[k+n]) {
+			continue
+		}
+		return tag, s[start:k], k + n - i, true
+	}
+	return "", "", 0, false
+}
+
+// mdLink parses "[label](destination "title")" at the start of s and returns
+// the label, destination and length
+func mdLink(s string) (string, string, int, bool) {
+	if !strings.HasPrefix(s, "[") {
+		return "", "", 0, false
+	}
+
+	depth, closing := 0, -1
+	for i := 0; i < len(s) && closing < 0; i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				closing = i
+			}
+		}
+	}
+	if closing < 0 || closing+1 >= len(s) || s[closing+1] != '(' {
+		return "", "", 0, false
+	}
+
+	rest := s[closing+2:]
+	trimmed := strings.TrimLeft(rest, " \t\n")
+
+	var dest string
+	var n int
+	if strings.HasPrefix(trimmed, "<") {
+		end := strings.IndexAny(trimmed, ">\n")
+		if end < 0 || trimmed[end] != '>' {
+			return "", "", 0, false
+		}
+		dest, n = trimmed[1:end], end+1
+	} else {
+		parens := 0
+	scan:
+		for ; n < len(trimmed); n++ {
+			switch trimmed[n] {
+			case ' ', '\t', '\n':
+				break scan
+			case '(':
+				parens++
+			case ')':
+				if parens == 0 {
+					break scan
+				}
+				parens--
+			}
+		}
+		dest = trimmed[:n]
+	}
+
+	// An optional title follows the destination
+	tail := trimmed[n:]
+	tail = strings.TrimLeft(tail, " \t\n")
+	if len(tail) > 0 && strings.ContainsRune(`"'(`, rune(tail[0])) {
+		quote := tail[0]
+		if quote == '(' {
+			quote = ')'
+		}
+		end := strings.IndexByte(tail[1:], quote)
+		if end < 0 {
+			return "", "", 0, false
+		}
+		tail = strings.TrimLeft(tail[end+2:], " \t\n")
+	}
+	if !strings.HasPrefix(tail, ")") {
+		return "", "", 0, false
+	}
+
+	// tail is a suffix of s ending the link with ")"
+	return s[1:closing], html.UnescapeString(dest), len(s) - len(tail) + 1, true
+}
+
+// mdCodeSpan returns the text of a code span, dropping one space on each
+// side when both are present
+func mdCodeSpan(code string) string {
+	code = strings.ReplaceAll(code, "\n", " ")
+	if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.Trim(code, " ") != "" {
+		code = code[1 : len(code)-1]
+	}
+	return code
+}
+
+// indentWidth returns the width of the leading whitespace of line with tabs
+// expanded to the next tab stop
+func indentWidth(line string) int {
+	width := 0
+	for _, c := range line {
+		switch c {
+		case ' ':
+			width++
+		case '\t':
+			width += mdTabWidth - width%mdTabWidth
+		default:
+			return width
+		}
+	}
+	return width
+}
+
+// stripIndent removes up to width columns of leading whitespace from line
+func stripIndent(line string, width int) string {
+	col := 0
+	for i, c := range line {
+		if col >= width {
+			return line[i:]
+		}
+		switch c {
+		case ' ':
+			col++
+		case '\t':
+			col += mdTabWidth - col%mdTabWidth
+		default:
+			return line[i:]
+		}
+	}
+	return ""
+}
+
+// nextNonBlank returns the index of the first non-blank line from i on
+func nextNonBlank(lines []string, i int) int {
+	for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+		i++
+	}
+	return i
+}
+
+// runLength returns the number of repetitions of s[i] starting at i
+func runLength(s string, i int) int {
+	n := 1
+	for i+n < len(s) && s[i+n] == s[i] {
+		n++
+	}
+	return n
+}
+
+func isOrderedMarker(marker string) bool {
+	return marker[0] >= '0' && marker[0] <= '9'
+}
+
+func isASCIIPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+func isWordByte(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// Markers that start a Markdown block when found at the start of a paragraph
+var mdLeadingMarker = regexp.MustCompile(`^(?:[#>+=-]|\d{1,9}[.)])`)
+
+// mdRenderBlock renders a top-level node as a Markdown block
+func mdRenderBlock(n Node) string {
+	elem, ok := asElement(n)
+	if !ok {
+		if s, ok := n.(string); ok {
+			return mdParagraphText([]Node{s})
+		}
+		return ""
+	}
+
+	switch elem.Tag {
+	case "h3":
+		return "## " + mdRenderInline(elem.Children)
+	case "h4":
+		return "### " + mdRenderInline(elem.Children)
+	case "hr":
+		return "---"
+	case "pre":
+		code := nodeText(elem)
+		fence := strings.Repeat("`", max(3, longestRun(code, '`')+1))
+		return fence + "\n" + code + "\n" + fence
+	case "blockquote", "aside":
+		return mdPrefixLines(mdRenderFlow(elem.Children), "> ", "> ")
+	case "ul", "ol":
+		return mdRenderList(elem)
+	case "figure":
+		return mdRenderFigure(elem)
+	case "img", "video", "iframe":
+		return mdRenderMedia(elem, "")
+	}
+	return mdParagraphText([]Node{elem})
+}
+
+// mdParagraphText renders inline nodes as a paragraph, escaping characters
+// that would start another block
+func mdParagraphText(nodes []Node) string {
+	text := mdRenderInline(nodes)
+	if loc := mdLeadingMarker.FindStringIndex(text); loc != nil {
+		text = text[:loc[1]-1] + "\\" + text[loc[1]-1:]
+	}
+	return text
+}
+
+// mdRenderFlow renders the children of a quote or a list item, where
+// inline content is mixed with blocks, as consecutive Markdown blocks
+func mdRenderFlow(children []Node) string {
+	var b strings.Builder
+	write := func(block string, tight bool) {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+			if !tight {
+				b.WriteString("\n")
+			}
+		}
+		b.WriteString(block)
+	}
+
+	var inline []Node
+	for _, child := range children {
+		if !isBlock(child, false) {
+			inline = append(inline, child)
+			continue
+		}
+		if text := mdParagraphText(inline); strings.TrimSpace(text) != "" {
+			write(text, false)
+		}
+		inline = nil
+		if block := mdRenderBlock(child); block != "" {
+			// Nested lists need no blank line before them
+			write(block, isTag(child, "ul") || isTag(child, "ol"))
+		}
+	}
+	if text := mdParagraphText(inline); strings.TrimSpace(text) != "" {
+		write(text, false)
+	}
+	return b.String()
+}
+
+// mdPrefixLines prefixes the first line of block with first and the others
+// with rest, leaving blank lines without trailing spaces
+func mdPrefixLines(block, first, rest string) string {
+	lines := strings.Split(block, "\n")
+	for i, line := range lines {
+		prefix := rest
+		if i == 0 {
+			prefix = first
+		}
+		if line == "" {
+			prefix = strings.TrimRight(prefix, " ")
+		}
+		lines[i] = prefix + line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// mdRenderList renders a list, with the blocks of its items indented under
+// their markers
+func mdRenderList(list NodeElement) string {
+	var items []string
+	number := 0
+	for _, item := range list.Children {
+		li, ok := asElement(item)
+		if !ok {
+			continue
+		}
+		number++
+		marker := "- "
+		if list.Tag == "ol" {
+			marker = fmt.Sprintf("%d. ", number)
+		}
+		items = append(items, mdPrefixLines(mdRenderFlow(li.Children), marker, strings.Repeat(" ", len(marker))))
+	}
+	return strings.Join(items, "\n")
+}
+
+// mdRenderFigure renders the media of a figure with its caption
+func mdRenderFigure(figure NodeElement) string {
+	var media []NodeElement
+	var caption string
+	for _, child := range figure.Children {
+		elem, ok := asElement(child)
+		if !ok {
+			continue
+		}
+		if elem.Tag == "figcaption" {
+			caption = mdRenderInline(elem.Children)
+			continue
+		}
+		media = append(media, elem)
+	}
+
+	var blocks []string
+	for _, elem := range media {
+		if block := mdRenderMedia(elem, caption); block != "" {
+			blocks = append(blocks, block)
+		}
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+// mdRenderMedia renders an image, a video or an embed. Images show the
+// caption as alt text; the caption of other media follows in italics.
+func mdRenderMedia(elem NodeElement, caption string) string {
+	src := elem.Attrs["src"]
+	if src == "" {
+		return ""
+	}
+
+	var block string
+	switch elem.Tag {
+	case "img":
+		return "![" + caption + "](" + mdDestination(src) + ")"
+	case "iframe":
+		block = embeddedURL(src)
+	default:
+		block = "<" + src + ">"
+		if !mdAuto.MatchString(block) {
+			block = "[" + mdEscape(src) + "](" + mdDestination(src) + ")"
+		}
+	}
+	if caption != "" {
+		block += "\n\n*" + caption + "*"
+	}
+	return block
+}
+
+// embeddedURL returns the URL of the media embedded by an iframe with src
+func embeddedURL(src string) string {
+	u, err := url.Parse(src)
+	if err == nil && strings.HasPrefix(u.Path, "/embed/") {
+		if target := u.Query().Get("url"); target != "" {
+			return target
+		}
+	}
+	return src
+}
+
+// mdRenderInline renders inline nodes as Markdown
+func mdRenderInline(nodes []Node) string {
+	var b strings.Builder
+	for i, n := range nodes {
+		if s, ok := n.(string); ok {
+			text := mdEscape(strings.ReplaceAll(s, "\n", " "))
+			// An exclamation mark before a link would make it an image
+			if i+1 < len(nodes) && isTag(nodes[i+1], "a") && strings.HasSuffix(text, "!") {
+				text = text[:len(text)-1] + "\\!"
+			}
+			b.WriteString(text)
+			continue
+		}
+		elem, ok := asElement(n)
+		if !ok {
+			continue
+		}
+
+		switch elem.Tag {
+		case "br":
+			b.WriteString("\\\n")
+		case "code":
+			code := strings.ReplaceAll(nodeText(elem), "\n", " ")
+			fence := strings.Repeat("`", longestRun(code, '`')+1)
+			if strings.HasPrefix(code, "`") || strings.HasSuffix(code, "`") {
+				code = " " + code + " "
+			}
+			b.WriteString(fence + code + fence)
+		case "a":
+			b.WriteString("[" + mdRenderInline(elem.Children) + "](" + mdDestination(elem.Attrs["href"]) + ")")
+		case "img":
+			if src := elem.Attrs["src"]; src != "" {
+				b.WriteString("![](" + mdDestination(src) + ")")
+			}
+		default:
+			inner := mdRenderInline(elem.Children)
+			delim := map[string]string{"b": "**", "strong": "**", "i": "*", "em": "*", "s": "~~"}[elem.Tag]
+			if delim != "" && strings.TrimSpace(inner) != "" {
+				// Delimiters must touch the text they enclose
+				trimmed := strings.TrimSpace(inner)
+				lead := inner[:strings.Index(inner, trimmed)]
+				trail := inner[len(lead)+len(trimmed):]
+				inner = lead + delim + trimmed + delim + trail
+			}
+			b.WriteString(inner)
+		}
+	}
+	return b.String()
+}
+
+// mdEscape escapes characters with a meaning in inline Markdown
+func mdEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte("\\`*_[]<>~&", s[i]) >= 0 {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// mdDestination returns a link destination, enclosed in angle brackets when
+// it contains characters that would end it
+func mdDestination(dest string) string {
+	if strings.ContainsAny(dest, " ()<>") {
+		return "<" + strings.NewReplacer("<", "%3C", ">", "%3E").Replace(dest) + ">"
+	}
+	return dest
+}
+
+// longestRun returns the length of the longest run of c in s
+func longestRun(s string, c byte) int {
+	longest, run := 0, 0
+	for i := 0; i < len(s); i++ {
+		if s[i] != c {
+			run = 0
+			continue
+		}
+		run++
+		longest = max(longest, run)
+	}
+	return longest
+}
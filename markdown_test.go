@@ -0,0 +1,156 @@
+package telegraph_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/smirnoffmg/telegraph"
+)
+
+func TestMarkdownToContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		wantJSON string
+	}{
+		{
+			name:     "Headings",
+			markdown: "# One\n\n### Three\n\nTwo\n---",
+			wantJSON: `[{"tag":"h3","children":["One"]},{"tag":"h4","children":["Three"]},{"tag":"h3","children":["Two"]}]`,
+		},
+		{
+			name:     "Inline formatting",
+			markdown: "Some *em*, **strong**, ~~gone~~, `code` and snake_case",
+			wantJSON: `[{"tag":"p","children":["Some ",{"tag":"em","children":["em"]},", ",{"tag":"strong","children":["strong"]},", ",` +
+				`{"tag":"s","children":["gone"]},", ",{"tag":"code","children":["code"]}," and snake_case"]}]`,
+		},
+		{
+			name:     "Links and line breaks",
+			markdown: "A [link](https://example.com \"Title\")  \nand <https://example.org>\\*",
+			wantJSON: `[{"tag":"p","children":["A ",{"tag":"a","attrs":{"href":"https://example.com"},"children":["link"]},{"tag":"br"},` +
+				`"and ",{"tag":"a","attrs":{"href":"https://example.org"},"children":["https://example.org"]},"*"]}]`,
+		},
+		{
+			name:     "Image alone becomes a figure",
+			markdown: "![A cat](cat.png)",
+			wantJSON: `[{"tag":"figure","children":[{"tag":"img","attrs":{"src":"cat.png"}},{"tag":"figcaption","children":["A cat"]}]}]`,
+		},
+		{
+			name:     "Nested lists",
+			markdown: "- one\n- two\n  - nested\n\n1. first\n2. second",
+			wantJSON: `[{"tag":"ul","children":[{"tag":"li","children":["one"]},{"tag":"li","children":["two",{"tag":"ul","children":[{"tag":"li","children":["nested"]}]}]}]},` +
+				`{"tag":"ol","children":[{"tag":"li","children":["first"]},{"tag":"li","children":["second"]}]}]`,
+		},
+		{
+			name:     "Block quote paragraphs",
+			markdown: "> quoted\n> text\n>\n> more",
+			wantJSON: `[{"tag":"blockquote","children":["quoted text",{"tag":"br"},{"tag":"br"},"more"]}]`,
+		},
+		{
+			name:     "Code blocks keep whitespace",
+			markdown: "```go\nfunc main() {\n\tprintln(\"*\")\n}\n```\n\n    indented\n\n***",
+			wantJSON: `[{"tag":"pre","children":["func main() {\n\tprintln(\"*\")\n}"]},{"tag":"pre","children":["indented"]},{"tag":"hr"}]`,
+		},
+		{
+			name:     "Embeds",
+			markdown: "https://youtu.be/dQw4w9WgXcQ",
+			wantJSON: `[{"tag":"figure","children":[{"tag":"iframe","attrs":{"src":"/embed/youtube?url=https%3A%2F%2Fwww.youtube.com%2Fwatch%3Fv%3DdQw4w9WgXcQ"}}]}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := telegraph.MarkdownToContent(tt.markdown)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			got, err := json.Marshal(content)
+			if err != nil {
+				t.Fatalf("Failed to marshal content: %v", err)
+			}
+			if string(got) != tt.wantJSON {
+				t.Errorf("Expected %s, got %s", tt.wantJSON, got)
+			}
+		})
+	}
+}
+
+func TestContentToMarkdown(t *testing.T) {
+	content := []telegraph.Node{
+		telegraph.NodeElement{Tag: "h3", Children: []telegraph.Node{"Heading"}},
+		telegraph.NodeElement{Tag: "p", Children: []telegraph.Node{
+			"1. Not a list * ",
+			telegraph.NodeElement{Tag: "strong", Children: []telegraph.Node{"bold"}},
+			" ",
+			telegraph.NodeElement{Tag: "a", Attrs: map[string]string{"href": "https://example.com/a_(b)"}, Children: []telegraph.Node{"link"}},
+			telegraph.NodeElement{Tag: "br"},
+			telegraph.NodeElement{Tag: "code", Children: []telegraph.Node{"a`b"}},
+		}},
+		telegraph.NodeElement{Tag: "ol", Children: []telegraph.Node{
+			telegraph.NodeElement{Tag: "li", Children: []telegraph.Node{"one"}},
+			telegraph.NodeElement{Tag: "li", Children: []telegraph.Node{"two", telegraph.NodeElement{Tag: "ul", Children: []telegraph.Node{
+				telegraph.NodeElement{Tag: "li", Children: []telegraph.Node{"nested"}},
+			}}}},
+		}},
+		telegraph.NodeElement{Tag: "figure", Children: []telegraph.Node{
+			telegraph.NodeElement{Tag: "img", Attrs: map[string]string{"src": "/file/cat.png"}},
+			telegraph.NodeElement{Tag: "figcaption", Children: []telegraph.Node{"A cat"}},
+		}},
+		telegraph.NodeElement{Tag: "blockquote", Children: []telegraph.Node{"quote", telegraph.NodeElement{Tag: "br"}, "more"}},
+		telegraph.NodeElement{Tag: "pre", Children: []telegraph.Node{"```\ncode"}},
+	}
+
+	want := "## Heading\n\n" +
+		"1\\. Not a list \\* **bold** [link](<https://example.com/a_(b)>)\\\n``a`b``\n\n" +
+		"1. one\n2. two\n   - nested\n\n" +
+		"![A cat](/file/cat.png)\n\n" +
+		"> quote\\\n> more\n\n" +
+		"````\n```\ncode\n````\n"
+	got := telegraph.ContentToMarkdown(content)
+	if got != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, got)
+	}
+
+	roundTrip, err := telegraph.MarkdownToContent(got)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !telegraph.Equal(roundTrip, content) {
+		t.Errorf("Expected the Markdown to convert back to the content, got diff:\n%s", telegraph.UnifiedDiff(content, roundTrip))
+	}
+}
+
+func TestContentToMarkdownRoundTrip(t *testing.T) {
+	el := func(tag string, children ...telegraph.Node) telegraph.NodeElement {
+		return telegraph.NodeElement{Tag: tag, Children: children}
+	}
+	link := telegraph.NodeElement{Tag: "a", Attrs: map[string]string{"href": "x.png"}, Children: []telegraph.Node{"link"}}
+
+	tests := []struct {
+		name    string
+		content []telegraph.Node
+	}{
+		{"exclamation before link", []telegraph.Node{el("p", "Wow!", link, " and !", el("em", link))}},
+		{"code in quote", []telegraph.Node{el("blockquote", "Look:", el("pre", "a\n  b\n\nc"), "after")}},
+		{"heading and list in quote", []telegraph.Node{el("blockquote", el("h4", "Title"), el("ul", el("li", "one"), el("li", "two")))}},
+		{"nested quote", []telegraph.Node{el("blockquote", "outer", el("blockquote", "inner", el("br"), "more"))}},
+		{"code in list", []telegraph.Node{el("ol", el("li", "first", el("pre", "x := 1\n  y")), el("li", "second"))}},
+		{"quote and list in list", []telegraph.Node{el("ul",
+			el("li", "a", el("blockquote", "quoted"), "b"),
+			el("li", el("ol", el("li", "deep", el("ul", el("li", "deeper"))))),
+		)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			markdown := telegraph.ContentToMarkdown(tt.content)
+			got, err := telegraph.MarkdownToContent(markdown)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !telegraph.Equal(got, tt.content) {
+				t.Errorf("Expected %q to convert back to the content, got diff:\n%s", markdown, telegraph.UnifiedDiff(tt.content, got))
+			}
+		})
+	}
+}
@@ -0,0 +1,295 @@
+package telegraph
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// pageURLPrefix is the URL of a page without its path
+const pageURLPrefix = "https://telegra.ph/"
+
+// Restore republishes the pages of the backup archive in dir, written by
+// Backup, into the account with the given access token. See Migrate for
+// how pages are republished and how an interrupted restore is resumed.
+func (c *Client) Restore(ctx context.Context, accessToken, dir string, done map[string]string,
+	copied func(from, to string) error,
+) (map[string]string, error) {
+	index, err := ReadBackup(dir)
+	if err != nil {
+		return done, err
+	}
+	pages := make([]Page, 0, len(index.Pages))
+	for _, entry := range index.Pages {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(entry.File)))
+		if err != nil {
+			return done, fmt.Errorf("failed to read backup of %s: %w", entry.Path, err)
+		}
+		var page Page
+		if err := json.Unmarshal(data, &page); err != nil {
+			return done, fmt.Errorf("failed to decode backup of %s: %w", entry.Path, err)
+		}
+		page.Path = entry.Path
+		pages = append(pages, page)
+	}
+
+	m := newMigration(c, accessToken, pages, done, copied, func(ctx context.Context, src string) (string, error) {
+		file, ok := index.Assets[src]
+		if !ok {
+			return src, nil
+		}
+		return c.UploadFile(ctx, filepath.Join(dir, filepath.FromSlash(file)))
+	})
+	return m.run(ctx)
+}
+
+// Migrate republishes all pages of the account with the access token
+// fromToken into the account with the access token toToken, which is how
+// pages are moved: Telegraph pages can only be edited by the account that
+// created them. It returns the map of the paths of the original pages to
+// the paths of their copies.
+//
+// Pages are created oldest first, so they are listed in the same order.
+// Files uploaded to Telegraph that the pages show are uploaded again and
+// links between the pages are rewritten to the copies.
+//
+// copied, if not nil, is called as soon as a page was copied, so that its
+// path can be recorded even if the migration is interrupted; an error from
+// it stops the migration. On failure the paths of the pages copied so far
+// are returned along with the error. Passing them as done to the next call
+// resumes the migration without creating those pages again. Links of the
+// copies made before are only rewritten if they still point to the
+// original pages.
+func (c *Client) Migrate(ctx context.Context, fromToken, toToken string, done map[string]string,
+	copied func(from, to string) error,
+) (map[string]string, error) {
+	pages, err := c.getAllPagesWithContent(ctx, fromToken)
+	if err != nil {
+		return done, err
+	}
+
+	m := newMigration(c, toToken, pages, done, copied, func(ctx context.Context, src string) (string, error) {
+		u, ok := c.fileURL(src)
+		if !ok {
+			return src, nil
+		}
+		data, err := c.download(ctx, u)
+		switch {
+		case errors.Is(err, errFileNotFound):
+			return src, nil
+		case err != nil:
+			return "", err
+		}
+		return c.Upload(ctx, path.Base(u.Path), bytes.NewReader(data))
+	})
+	return m.run(ctx)
+}
+
+// migration republishes pages into an account
+type migration struct {
+	client      *Client
+	accessToken string
+	pages       []Page            // pages to republish, newest first
+	source      map[string]bool   // paths of the pages
+	paths       map[string]string // paths of the pages to the paths of their copies
+	uploads     map[string]string // src of files to the src of their copies
+	copied      func(from, to string) error
+	upload      func(ctx context.Context, src string) (string, error)
+}
+
+// migrationEdit is a copy whose links to other pages are to be rewritten
+type migrationEdit struct {
+	page    Page   // the original page
+	content []Node // content of the copy
+}
+
+// newMigration returns a migration of pages of which those in done were
+// already republished. copied is called with the paths of every page
+// created, if not nil. upload uploads the file src refers to again and
+// returns its new src, or src itself if it is not to be uploaded.
+func newMigration(c *Client, accessToken string, pages []Page, done map[string]string,
+	copied func(from, to string) error, upload func(ctx context.Context, src string) (string, error),
+) *migration {
+	m := &migration{
+		client:      c,
+		accessToken: accessToken,
+		pages:       pages,
+		source:      make(map[string]bool, len(pages)),
+		paths:       make(map[string]string, len(pages)),
+		uploads:     make(map[string]string),
+		copied:      copied,
+		upload:      upload,
+	}
+	for _, page := range pages {
+		m.source[page.Path] = true
+	}
+	for from, to := range done {
+		m.paths[from] = to
+	}
+	return m
+}
+
+// run republishes the pages in two passes. The first creates the missing
+// pages, with links to pages that are not created yet left as they are, and
+// the second edits the copies whose links could not all be rewritten then.
+func (m *migration) run(ctx context.Context) (map[string]string, error) {
+	var edits []migrationEdit
+	for i := len(m.pages) - 1; i >= 0; i-- {
+		page := m.pages[i]
+		if to, ok := m.paths[page.Path]; ok {
+			// Copies made before may not have had their links rewritten yet
+			if !m.hasLinks(page.Content) {
+				continue
+			}
+			cp, err := m.client.GetPageWith(ctx, GetPageRequest{Path: to, ReturnContent: true})
+			if err != nil {
+				return m.paths, fmt.Errorf("failed to get copy of %s: %w", page.Path, err)
+			}
+			if m.hasLinks(cp.Content) {
+				edits = append(edits, migrationEdit{page: page, content: cp.Content})
+			}
+			continue
+		}
+
+		content, complete, err := m.content(ctx, page.Content)
+		if err != nil {
+			return m.paths, err
+		}
+		created, err := m.client.CreatePageWith(ctx, CreatePageRequest{
+			AccessToken: m.accessToken,
+			Title:       page.Title,
+			AuthorName:  page.AuthorName,
+			AuthorURL:   page.AuthorURL,
+			Content:     content,
+		})
+		if err != nil {
+			return m.paths, fmt.Errorf("failed to copy %s: %w", page.Path, err)
+		}
+		m.paths[page.Path] = created.Path
+		if m.copied != nil {
+			if err := m.copied(page.Path, created.Path); err != nil {
+				return m.paths, err
+			}
+		}
+		if !complete {
+			edits = append(edits, migrationEdit{page: page, content: content})
+		}
+	}
+
+	// Files of the copies are uploaded already, only links are rewritten
+	for _, edit := range edits {
+		page := edit.page
+		if _, err := m.client.EditPageWith(ctx, EditPageRequest{
+			AccessToken: m.accessToken,
+			Path:        m.paths[page.Path],
+			Title:       page.Title,
+			Content:     m.links(edit.content),
+			AuthorName:  String(page.AuthorName),
+			AuthorURL:   String(page.AuthorURL),
+		}); err != nil {
+			return m.paths, fmt.Errorf("failed to update links of %s: %w", page.Path, err)
+		}
+	}
+	return m.paths, nil
+}
+
+// content returns content with its files uploaded again and its links to
+// the pages rewritten to their copies. It reports whether all of those
+// links could be rewritten.
+func (m *migration) content(ctx context.Context, content []Node) ([]Node, bool, error) {
+	var err error
+	complete := true
+	rewritten := RewriteURLs(content, func(tag, attr, value string) string {
+		switch {
+		case err != nil:
+			return value
+		case attr == "src" && (tag == "img" || tag == "video"):
+			uploaded, ok := m.uploads[value]
+			if !ok {
+				if uploaded, err = m.upload(ctx, value); err != nil {
+					return value
+				}
+				m.uploads[value] = uploaded
+			}
+			return uploaded
+		case attr == "href":
+			link, internal := m.link(value)
+			if internal && link == value {
+				complete = false
+			}
+			return link
+		}
+		return value
+	})
+	return rewritten, complete, err
+}
+
+// links returns content with its links to the pages rewritten to their copies
+func (m *migration) links(content []Node) []Node {
+	return RewriteURLs(content, func(_, attr, value string) string {
+		if attr != "href" {
+			return value
+		}
+		link, _ := m.link(value)
+		return link
+	})
+}
+
+// hasLinks reports whether content links to any of the pages
+func (m *migration) hasLinks(content []Node) bool {
+	found := false
+	RewriteURLs(content, func(_, attr, value string) string {
+		if _, internal := m.pagePath(value); attr == "href" && internal {
+			found = true
+		}
+		return value
+	})
+	return found
+}
+
+// link returns href rewritten to the copy of the page it refers to, and
+// whether it refers to one of the pages. Links to pages not copied yet are
+// returned unchanged.
+func (m *migration) link(href string) (string, bool) {
+	from, internal := m.pagePath(href)
+	if !internal {
+		return href, false
+	}
+	to, ok := m.paths[from]
+	if !ok {
+		return href, true
+	}
+	link := pageURLPrefix + to
+	if u, err := url.Parse(href); err == nil && u.Fragment != "" {
+		link += "#" + u.Fragment
+	}
+	return link, true
+}
+
+// pagePath returns the path of the page href refers to and whether it is
+// one of the pages
+func (m *migration) pagePath(href string) (string, bool) {
+	p, ok := telegraphPagePath(href)
+	return p, ok && m.source[p]
+}
+
+// telegraphPagePath returns the path of the Telegraph page href links to,
+// either by its URL or by its path, and whether it links to one
+func telegraphPagePath(href string) (string, bool) {
+	u, err := url.Parse(href)
+	if err != nil {
+		return "", false
+	}
+	if _, ok := telegraphHosts[u.Host]; !ok && (u.Host != "" || !strings.HasPrefix(u.Path, "/")) {
+		return "", false
+	}
+	p := strings.Trim(u.Path, "/")
+	return p, p != ""
+}
@@ -0,0 +1,219 @@
+package telegraph_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/smirnoffmg/telegraph"
+)
+
+// migrateServer serves two accounts. The old account has two pages linking
+// to each other, pages created in the new account are named after their
+// titles and serve the content they were last sent, and uploads are
+// numbered.
+type migrateServer struct {
+	mu      sync.Mutex
+	calls   []string
+	bodies  map[string]map[string]interface{} // last request body of each page created or edited
+	uploads int
+}
+
+func newMigrateServer() *migrateServer {
+	return &migrateServer{bodies: make(map[string]map[string]interface{})}
+}
+
+func (s *migrateServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	route := strings.TrimPrefix(r.URL.Path, "/")
+	s.calls = append(s.calls, route)
+
+	switch {
+	case route == "file/cat.png":
+		_, _ = w.Write([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"))
+		return
+	case route == "upload":
+		s.uploads++
+		fmt.Fprintf(w, `[{"src":"/file/copy-%d.png"}]`, s.uploads)
+		return
+	}
+
+	var body map[string]interface{}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	var result interface{}
+	switch method, arg, _ := strings.Cut(route, "/"); method {
+	case "getPageList":
+		result = telegraph.PageList{TotalCount: 2, Pages: []telegraph.Page{{Path: "Second-10-19"}, {Path: "First-10-18"}}}
+	case "getPage":
+		result = migratePages()[arg]
+		if copyBody, ok := s.bodies[arg]; ok {
+			result = map[string]interface{}{"path": arg, "title": copyBody["title"], "content": copyBody["content"]}
+		}
+	case "createPage", "editPage":
+		copyPath := arg
+		if copyPath == "" {
+			copyPath = body["title"].(string) + "-Copy"
+		}
+		s.bodies[copyPath] = body
+		result = telegraph.Page{Path: copyPath, URL: "https://telegra.ph/" + copyPath}
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "result": result})
+}
+
+// migratePages returns the pages of the old account by path
+func migratePages() map[string]telegraph.Page {
+	link := func(href string) telegraph.NodeElement {
+		return telegraph.NodeElement{Tag: "a", Attrs: map[string]string{"href": href}, Children: []telegraph.Node{"link"}}
+	}
+	return map[string]telegraph.Page{
+		"First-10-18": {Path: "First-10-18", URL: "https://telegra.ph/First-10-18", Title: "First", AuthorName: "Anonymous",
+			Content: []telegraph.Node{link("https://telegra.ph/Second-10-19"), link("https://example.com/")}},
+		"Second-10-19": {Path: "Second-10-19", URL: "https://telegra.ph/Second-10-19", Title: "Second",
+			Content: []telegraph.Node{
+				link("/First-10-18#intro"),
+				telegraph.NodeElement{Tag: "img", Attrs: map[string]string{"src": "/file/cat.png"}},
+			}},
+	}
+}
+
+// methods returns the recorded calls and forgets them
+func (s *migrateServer) methods() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	calls := s.calls
+	s.calls = nil
+	return calls
+}
+
+// content returns the content last sent for the copy at path as JSON
+func (s *migrateServer) content(path string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, _ := json.Marshal(s.bodies[path]["content"])
+	return string(data)
+}
+
+func TestMigrate(t *testing.T) {
+	api := newMigrateServer()
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	client := telegraph.NewClient(server.Client())
+	client.SetBaseURL(server.URL + "/")
+	client.SetUploadURL(server.URL + "/upload")
+
+	var copied []string
+	paths, err := client.WithToken("new").Migrate(context.Background(), "old", nil, func(from, to string) error {
+		copied = append(copied, from+" "+to)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if want := []string{"First-10-18 First-Copy", "Second-10-19 Second-Copy"}; !reflect.DeepEqual(copied, want) {
+		t.Errorf("Expected copies to be reported as %v, got %v", want, copied)
+	}
+	if want := map[string]string{"First-10-18": "First-Copy", "Second-10-19": "Second-Copy"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("Expected paths %v, got %v", want, paths)
+	}
+	wantCalls := []string{
+		"getPageList", "getPage/Second-10-19", "getPage/First-10-18",
+		"createPage", "file/cat.png", "upload", "createPage", "editPage/First-Copy",
+	}
+	if calls := api.methods(); !reflect.DeepEqual(calls, wantCalls) {
+		t.Errorf("Expected calls %v, got %v", wantCalls, calls)
+	}
+
+	wantFirst := `[{"attrs":{"href":"https://telegra.ph/Second-Copy"},"children":["link"],"tag":"a"},` +
+		`{"attrs":{"href":"https://example.com/"},"children":["link"],"tag":"a"}]`
+	if got := api.content("First-Copy"); got != wantFirst {
+		t.Errorf("Expected first page content %s, got %s", wantFirst, got)
+	}
+	wantSecond := `[{"attrs":{"href":"https://telegra.ph/First-Copy#intro"},"children":["link"],"tag":"a"},` +
+		`{"attrs":{"src":"/file/copy-1.png"},"tag":"img"}]`
+	if got := api.content("Second-Copy"); got != wantSecond {
+		t.Errorf("Expected second page content %s, got %s", wantSecond, got)
+	}
+
+	// Resuming skips the pages copied before, whose links are up to date
+	paths, err = client.Migrate(context.Background(), "old", "new", map[string]string{"First-10-18": "First-Copy"}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(paths) != 2 {
+		t.Errorf("Expected 2 paths, got %v", paths)
+	}
+	wantCalls = []string{
+		"getPageList", "getPage/Second-10-19", "getPage/First-10-18", "getPage/First-Copy",
+		"file/cat.png", "upload", "createPage",
+	}
+	if calls := api.methods(); !reflect.DeepEqual(calls, wantCalls) {
+		t.Errorf("Expected calls %v, got %v", wantCalls, calls)
+	}
+
+	// Copies with links left to the original pages are edited without
+	// uploading their files again
+	api.mu.Lock()
+	api.bodies["First-Copy"]["content"] = []interface{}{
+		map[string]interface{}{"tag": "a", "attrs": map[string]interface{}{"href": "https://telegra.ph/Second-10-19"}, "children": []interface{}{"link"}},
+		map[string]interface{}{"tag": "img", "attrs": map[string]interface{}{"src": "/file/copy-1.png"}},
+	}
+	api.mu.Unlock()
+	if _, err := client.Migrate(context.Background(), "old", "new", map[string]string{"First-10-18": "First-Copy"}, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	wantCalls = []string{
+		"getPageList", "getPage/Second-10-19", "getPage/First-10-18", "getPage/First-Copy",
+		"file/cat.png", "upload", "createPage", "editPage/First-Copy",
+	}
+	if calls := api.methods(); !reflect.DeepEqual(calls, wantCalls) {
+		t.Errorf("Expected calls %v, got %v", wantCalls, calls)
+	}
+	wantFirst = `[{"attrs":{"href":"https://telegra.ph/Second-Copy"},"children":["link"],"tag":"a"},` +
+		`{"attrs":{"src":"/file/copy-1.png"},"tag":"img"}]`
+	if got := api.content("First-Copy"); got != wantFirst {
+		t.Errorf("Expected first page content %s, got %s", wantFirst, got)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	api := newMigrateServer()
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	client := telegraph.NewClient(server.Client())
+	client.SetBaseURL(server.URL + "/")
+	client.SetUploadURL(server.URL + "/upload")
+
+	dir := t.TempDir()
+	if _, err := client.Backup(context.Background(), "old", dir); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	api.methods()
+
+	paths, err := client.Restore(context.Background(), "new", dir, nil, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if want := map[string]string{"First-10-18": "First-Copy", "Second-10-19": "Second-Copy"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("Expected paths %v, got %v", want, paths)
+	}
+	// The image is uploaded from the archive
+	if calls, want := api.methods(), []string{"createPage", "upload", "createPage", "editPage/First-Copy"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("Expected calls %v, got %v", want, calls)
+	}
+	if got := api.content("Second-Copy"); !strings.Contains(got, "/file/copy-1.png") || !strings.Contains(got, "First-Copy#intro") {
+		t.Errorf("Expected rewritten content, got %s", got)
+	}
+
+	if _, err := client.Restore(context.Background(), "new", t.TempDir(), nil, nil); err == nil {
+		t.Error("Expected error restoring a missing backup, got nil")
+	}
+}
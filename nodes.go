@@ -0,0 +1,100 @@
+package telegraph
+
+import "strings"
+
+// Tags that start a new block of content. Text flow is reset around them.
+var blockTags = map[string]struct{}{
+	"aside":      {},
+	"blockquote": {},
+	"figcaption": {},
+	"figure":     {},
+	"h3":         {},
+	"h4":         {},
+	"hr":         {},
+	"li":         {},
+	"ol":         {},
+	"p":          {},
+	"pre":        {},
+	"ul":         {},
+}
+
+// Tags that may appear directly at the top level of a page next to blocks
+var mediaTags = map[string]struct{}{
+	"iframe": {},
+	"img":    {},
+	"video":  {},
+}
+
+// Tags that are meaningful without any children
+var voidTags = map[string]struct{}{
+	"br":     {},
+	"hr":     {},
+	"iframe": {},
+	"img":    {},
+	"video":  {},
+}
+
+// asElement returns the NodeElement held by n. Besides NodeElement values it
+// accepts pointers and the generic maps produced by decoding Page.Content.
+func asElement(n Node) (NodeElement, bool) {
+	switch v := n.(type) {
+	case NodeElement:
+		return v, true
+	case *NodeElement:
+		if v == nil {
+			return NodeElement{}, false
+		}
+		return *v, true
+	case map[string]interface{}:
+		tag, ok := v["tag"].(string)
+		if !ok {
+			return NodeElement{}, false
+		}
+		elem := NodeElement{Tag: tag}
+		if attrs, ok := v["attrs"].(map[string]interface{}); ok {
+			elem.Attrs = make(map[string]string, len(attrs))
+			for key, value := range attrs {
+				if s, ok := value.(string); ok {
+					elem.Attrs[key] = s
+				}
+			}
+		}
+		if children, ok := v["children"].([]interface{}); ok {
+			elem.Children = make([]Node, 0, len(children))
+			for _, child := range children {
+				elem.Children = append(elem.Children, child)
+			}
+		}
+		return elem, true
+	}
+	return NodeElement{}, false
+}
+
+// isBlock reports whether n is an element that breaks the inline text flow
+func isBlock(n Node, topLevel bool) bool {
+	elem, ok := asElement(n)
+	if !ok {
+		return false
+	}
+	if _, ok := blockTags[elem.Tag]; ok {
+		return true
+	}
+	_, ok = mediaTags[elem.Tag]
+	return ok && topLevel
+}
+
+// nodeText returns the concatenated text of n and its descendants
+func nodeText(n Node) string {
+	if s, ok := n.(string); ok {
+		return s
+	}
+	elem, ok := asElement(n)
+	if !ok {
+		return ""
+	}
+	var text strings.Builder
+	for _, child := range elem.Children {
+		text.WriteString(nodeText(child))
+	}
+	return text.String()
+}
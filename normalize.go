@@ -0,0 +1,227 @@
+package telegraph
+
+import "strings"
+
+// NormalizeContent returns a compact copy of content that renders the same way.
+// Insignificant whitespace is collapsed (but kept verbatim inside pre), adjacent
+// text nodes are merged, empty elements and attributes are removed and inline
+// content found at the top level is wrapped in paragraphs.
+func NormalizeContent(content []Node) []Node {
+	nodes := normalizeNodes(content, false)
+	nodes = normalizeFlow(nodes, true)
+	return wrapInline(nodes)
+}
+
+// normalizeNodes merges adjacent text nodes and normalizes child elements
+func normalizeNodes(nodes []Node, inPre bool) []Node {
+	var merged []Node
+	for _, n := range nodes {
+		if s, ok := n.(string); ok {
+			if last := len(merged) - 1; last >= 0 {
+				if prev, ok := merged[last].(string); ok {
+					merged[last] = prev + s
+					continue
+				}
+			}
+			merged = append(merged, s)
+			continue
+		}
+		if elem, ok := asElement(n); ok {
+			merged = append(merged, elem)
+		}
+	}
+
+	out := make([]Node, 0, len(merged))
+	for _, n := range merged {
+		if s, ok := n.(string); ok {
+			if !inPre {
+				s = collapseSpace(s)
+			}
+			if s != "" {
+				out = append(out, s)
+			}
+			continue
+		}
+		if elem, ok := normalizeElement(n.(NodeElement), inPre); ok {
+			out = append(out, elem)
+		}
+	}
+	return out
+}
+
+// normalizeElement normalizes the element and reports whether it should be kept
+func normalizeElement(elem NodeElement, inPre bool) (NodeElement, bool) {
+	pre := inPre || elem.Tag == "pre"
+	children := normalizeNodes(elem.Children, pre)
+	if _, ok := blockTags[elem.Tag]; ok && !pre {
+		children = normalizeFlow(children, false)
+	}
+
+	var attrs map[string]string
+	for key, value := range elem.Attrs {
+		if value == "" {
+			continue
+		}
+		if attrs == nil {
+			attrs = make(map[string]string)
+		}
+		attrs[key] = value
+	}
+
+	if len(children) == 0 {
+		children = nil
+		if _, ok := voidTags[elem.Tag]; !ok {
+			return NodeElement{}, false
+		}
+	}
+
+	return NodeElement{Tag: elem.Tag, Attrs: attrs, Children: children}, true
+}
+
+// normalizeFlow trims whitespace at the edges of every run of inline content
+// and collapses spaces that meet across inline element boundaries
+func normalizeFlow(nodes []Node, topLevel bool) []Node {
+	out := make([]Node, 0, len(nodes))
+	var segment []Node
+	flush := func() {
+		prevSpace := true
+		segment = collapseFlow(segment, &prevSpace)
+		out = append(out, trimTrailing(segment)...)
+		segment = nil
+	}
+
+	for _, n := range nodes {
+		if isBlock(n, topLevel) || isTag(n, "br") {
+			flush()
+			out = append(out, n)
+			continue
+		}
+		segment = append(segment, n)
+	}
+	flush()
+
+	return out
+}
+
+// collapseFlow drops leading spaces that follow another space in the flow
+func collapseFlow(nodes []Node, prevSpace *bool) []Node {
+	out := make([]Node, 0, len(nodes))
+	for _, n := range nodes {
+		if s, ok := n.(string); ok {
+			if *prevSpace {
+				s = strings.TrimLeft(s, " ")
+			}
+			if s == "" {
+				continue
+			}
+			*prevSpace = strings.HasSuffix(s, " ")
+			out = append(out, s)
+			continue
+		}
+
+		elem := n.(NodeElement)
+		switch {
+		case isBlock(elem, false) || elem.Tag == "br":
+			*prevSpace = true
+		case isVoid(elem) || elem.Tag == "pre":
+			*prevSpace = false
+		default:
+			elem.Children = collapseFlow(elem.Children, prevSpace)
+			if len(elem.Children) == 0 {
+				continue
+			}
+		}
+		out = append(out, elem)
+	}
+	return out
+}
+
+// trimTrailing removes trailing spaces from the last text of an inline run
+func trimTrailing(nodes []Node) []Node {
+	for len(nodes) > 0 {
+		last := len(nodes) - 1
+		if s, ok := nodes[last].(string); ok {
+			s = strings.TrimRight(s, " ")
+			if s == "" {
+				nodes = nodes[:last]
+				continue
+			}
+			nodes[last] = s
+			return nodes
+		}
+
+		elem := nodes[last].(NodeElement)
+		if isBlock(elem, false) || isVoid(elem) || elem.Tag == "pre" {
+			return nodes
+		}
+		elem.Children = trimTrailing(elem.Children)
+		if len(elem.Children) == 0 {
+			nodes = nodes[:last]
+			continue
+		}
+		nodes[last] = elem
+		return nodes
+	}
+	return nodes
+}
+
+// wrapInline wraps runs of inline top-level nodes into paragraphs
+func wrapInline(nodes []Node) []Node {
+	out := make([]Node, 0, len(nodes))
+	var run []Node
+	flush := func() {
+		for _, n := range run {
+			if !isTag(n, "br") {
+				out = append(out, NodeElement{Tag: "p", Children: trimTrailing(run)})
+				break
+			}
+		}
+		run = nil
+	}
+
+	for _, n := range nodes {
+		if isBlock(n, true) {
+			flush()
+			out = append(out, n)
+			continue
+		}
+		if len(run) == 0 && isTag(n, "br") {
+			continue
+		}
+		run = append(run, n)
+	}
+	flush()
+
+	return out
+}
+
+// collapseSpace replaces every run of HTML whitespace with a single space
+func collapseSpace(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range s {
+		switch r {
+		case ' ', '\t', '\n', '\r', '\f':
+			if !space {
+				b.WriteByte(' ')
+			}
+			space = true
+		default:
+			b.WriteRune(r)
+			space = false
+		}
+	}
+	return b.String()
+}
+
+// isTag reports whether n is an element with the given tag
+func isTag(n Node, tag string) bool {
+	elem, ok := asElement(n)
+	return ok && elem.Tag == tag
+}
+
+// isVoid reports whether the element is meaningful without children
+func isVoid(elem NodeElement) bool {
+	_, ok := voidTags[elem.Tag]
+	return ok
+}
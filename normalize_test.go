@@ -0,0 +1,102 @@
+package telegraph_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/smirnoffmg/telegraph"
+)
+
+func TestNormalizeContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  []telegraph.Node
+		wantJSON string
+	}{
+		{
+			name: "Drop whitespace between blocks",
+			content: []telegraph.Node{
+				"\n  ",
+				telegraph.NodeElement{Tag: "p", Attrs: map[string]string{}, Children: []telegraph.Node{"Hello"}},
+				"\n\t",
+				telegraph.NodeElement{Tag: "p", Children: []telegraph.Node{"World"}},
+				"\n",
+			},
+			wantJSON: `[{"tag":"p","children":["Hello"]},{"tag":"p","children":["World"]}]`,
+		},
+		{
+			name: "Collapse whitespace across inline elements",
+			content: []telegraph.Node{
+				telegraph.NodeElement{Tag: "p", Children: []telegraph.Node{
+					"  Hello,\n   ",
+					telegraph.NodeElement{Tag: "b", Children: []telegraph.Node{" big  "}},
+					" world  ",
+				}},
+			},
+			wantJSON: `[{"tag":"p","children":["Hello, ",{"tag":"b","children":["big "]},"world"]}]`,
+		},
+		{
+			name: "Preserve whitespace in pre",
+			content: []telegraph.Node{
+				telegraph.NodeElement{Tag: "pre", Children: []telegraph.Node{"a  b\n", "  c"}},
+			},
+			wantJSON: `[{"tag":"pre","children":["a  b\n  c"]}]`,
+		},
+		{
+			name: "Wrap top-level inline content",
+			content: []telegraph.Node{
+				"Hello ",
+				telegraph.NodeElement{Tag: "b", Children: []telegraph.Node{"world"}},
+				telegraph.NodeElement{Tag: "h3", Children: []telegraph.Node{"Title"}},
+				" tail ",
+			},
+			wantJSON: `[{"tag":"p","children":["Hello ",{"tag":"b","children":["world"]}]},` +
+				`{"tag":"h3","children":["Title"]},{"tag":"p","children":["tail"]}]`,
+		},
+		{
+			name: "Remove empty elements and attrs",
+			content: []telegraph.Node{
+				telegraph.NodeElement{Tag: "p", Children: []telegraph.Node{
+					telegraph.NodeElement{Tag: "b", Children: []telegraph.Node{"  "}},
+				}},
+				telegraph.NodeElement{Tag: "figure", Children: []telegraph.Node{
+					telegraph.NodeElement{Tag: "img", Attrs: map[string]string{"src": "/file/a.png", "href": ""}},
+					telegraph.NodeElement{Tag: "figcaption"},
+				}},
+				&telegraph.NodeElement{Tag: "hr", Attrs: map[string]string{}},
+			},
+			wantJSON: `[{"tag":"figure","children":[{"tag":"img","attrs":{"src":"/file/a.png"}}]},{"tag":"hr"}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(telegraph.NormalizeContent(tt.content))
+			if err != nil {
+				t.Fatalf("Failed to marshal content: %v", err)
+			}
+			if string(got) != tt.wantJSON {
+				t.Errorf("Expected\n%s\ngot\n%s", tt.wantJSON, string(got))
+			}
+		})
+	}
+}
+
+func TestHTMLToContentNormalizes(t *testing.T) {
+	htmlStr := "\n<p>One</p>\n\n<ul>\n  <li>a</li>\n  <li>b</li>\n</ul>\n<pre>x  y</pre>\nloose <i>text</i>\n"
+	want := `[{"tag":"p","children":["One"]},{"tag":"ul","children":[{"tag":"li","children":["a"]},{"tag":"li","children":["b"]}]},` +
+		`{"tag":"pre","children":["x  y"]},{"tag":"p","children":["loose ",{"tag":"i","children":["text"]}]}]`
+
+	content, err := telegraph.HTMLToContent(htmlStr)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	got, err := json.Marshal(content)
+	if err != nil {
+		t.Fatalf("Failed to marshal content: %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, string(got))
+	}
+}
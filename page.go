@@ -1,28 +1,30 @@
 package telegraph
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// MaxPageListLimit is the largest number of pages getPageList returns at once
+const MaxPageListLimit = 200
 
 // CreatePage creates a new page on Telegraph
 // See https://telegra.ph/api#createPage
 func (c *Client) CreatePage(accessToken, title string, content []Node, authorName, authorURL string) (*Page, error) {
-	body := map[string]interface{}{
-		"access_token": accessToken,
-		"title":        title,
-		"content":      content,
-		"author_name":  authorName,
-		"author_url":   authorURL,
-	}
-
-	var result CreatePageResponse
-	if err := c.doRequest("POST", "createPage", body, &result); err != nil {
-		return nil, fmt.Errorf("failed to create page: %w", err)
-	}
-
-	if !result.Ok {
-		return nil, ErrCreatePageFailed
-	}
+	return c.CreatePageWith(context.Background(), CreatePageRequest{
+		AccessToken: accessToken,
+		Title:       title,
+		Content:     content,
+		AuthorName:  authorName,
+		AuthorURL:   authorURL,
+	})
+}
 
-	return &result.Result, nil
+// CreatePageWith creates a new page on Telegraph
+// See https://telegra.ph/api#createPage
+func (c *Client) CreatePageWith(ctx context.Context, req CreatePageRequest) (*Page, error) {
+	return call[Page](ctx, c, "createPage", req, ErrCreatePageFailed)
 }
 
 func (c *Client) CreatePageFromHTML(accessToken, title, htmlContent, authorName, authorURL string) (*Page, error) {
@@ -34,88 +36,158 @@ func (c *Client) CreatePageFromHTML(accessToken, title, htmlContent, authorName,
 	return c.CreatePage(accessToken, title, content, authorName, authorURL)
 }
 
-// EditPage edits an existing page on Telegraph
+// EditPage edits an existing page on Telegraph.
+// Author fields are always sent, so empty values clear them; use PatchPage
+// to change only some fields.
 // See https://telegra.ph/api#editPage
 func (c *Client) EditPage(accessToken, path, title string, content []Node, authorName, authorURL string) (*Page, error) {
-	body := map[string]interface{}{
-		"access_token": accessToken,
-		"path":         path,
-		"title":        title,
-		"content":      content,
-		"author_name":  authorName,
-		"author_url":   authorURL,
-	}
+	return c.EditPageWith(context.Background(), EditPageRequest{
+		AccessToken: accessToken,
+		Path:        path,
+		Title:       title,
+		Content:     content,
+		AuthorName:  String(authorName),
+		AuthorURL:   String(authorURL),
+	})
+}
 
-	var result CreatePageResponse
-	if err := c.doRequest("POST", "editPage/"+path, body, &result); err != nil {
-		return nil, fmt.Errorf("failed to edit page: %w", err)
+// EditPageWith edits an existing page on Telegraph
+// See https://telegra.ph/api#editPage
+func (c *Client) EditPageWith(ctx context.Context, req EditPageRequest) (*Page, error) {
+	return call[Page](ctx, c, "editPage/"+req.Path, req, ErrEditPageFailed)
+}
+
+// PatchPage changes the fields of a page set in patch and leaves the others
+// as they are. Telegraph requires a title and content for every edit, so
+// the page is fetched first when patch lacks either of them.
+// See https://telegra.ph/api#editPage
+func (c *Client) PatchPage(accessToken, path string, patch PagePatch) (*Page, error) {
+	if patch.Content != nil && len(patch.Content) == 0 {
+		return nil, fmt.Errorf("%w: leave Content nil to keep the current content of %s", ErrEmptyContent, path)
+	}
+	if patch.Title == nil || patch.Content == nil {
+		current, err := c.GetPage(path, true)
+		if err != nil {
+			return nil, err
+		}
+		if patch.Title == nil {
+			patch.Title = String(current.Title)
+		}
+		if patch.Content == nil {
+			patch.Content = current.Content
+		}
+	}
+
+	return c.EditPageWith(context.Background(), EditPageRequest{
+		AccessToken: accessToken,
+		Path:        path,
+		Title:       *patch.Title,
+		Content:     patch.Content,
+		AuthorName:  patch.AuthorName,
+		AuthorURL:   patch.AuthorURL,
+	})
+}
+
+// UpdatePage fetches the page with its content, lets update modify it and
+// sends the author fields only if they changed. When nothing changed no
+// edit is made and the current page is returned.
+func (c *Client) UpdatePage(accessToken, path string, update func(*Page)) (*Page, error) {
+	current, err := c.GetPage(path, true)
+	if err != nil {
+		return nil, err
 	}
 
-	if !result.Ok {
-		return nil, ErrEditPageFailed
+	updated := *current
+	updated.Content = append([]Node(nil), current.Content...)
+	update(&updated)
+
+	patch := PagePatch{Title: String(updated.Title), Content: updated.Content}
+	if updated.AuthorName != current.AuthorName {
+		patch.AuthorName = String(updated.AuthorName)
+	}
+	if updated.AuthorURL != current.AuthorURL {
+		patch.AuthorURL = String(updated.AuthorURL)
+	}
+	if patch.AuthorName == nil && patch.AuthorURL == nil && updated.Title == current.Title &&
+		reflect.DeepEqual(updated.Content, current.Content) {
+		return current, nil
 	}
 
-	return &result.Result, nil
+	return c.PatchPage(accessToken, path, patch)
 }
 
 // GetPage retrieves a page from Telegraph
 // See https://telegra.ph/api#getPage
 func (c *Client) GetPage(path string, returnContent bool) (*Page, error) {
-	body := map[string]interface{}{
-		"return_content": returnContent,
-	}
-
-	var result GetPageResponse
-	if err := c.doRequest("GET", "getPage/"+path, body, &result); err != nil {
-		return nil, fmt.Errorf("failed to get page: %w", err)
-	}
-
-	if !result.Ok {
-		return nil, ErrGetPageFailed
-	}
+	return c.GetPageWith(context.Background(), GetPageRequest{Path: path, ReturnContent: returnContent})
+}
 
-	return &result.Result, nil
+// GetPageWith retrieves a page from Telegraph
+// See https://telegra.ph/api#getPage
+func (c *Client) GetPageWith(ctx context.Context, req GetPageRequest) (*Page, error) {
+	return call[Page](ctx, c, "getPage/"+req.Path, req, ErrGetPageFailed)
 }
 
 // GetPageList retrieves a list of pages for a Telegraph account
 // See https://telegra.ph/api#getPageList
 func (c *Client) GetPageList(accessToken string, offset, limit int) (*PageList, error) {
-	body := map[string]interface{}{
-		"access_token": accessToken,
-		"offset":       offset,
-		"limit":        limit,
-	}
+	return c.GetPageListWith(context.Background(), GetPageListRequest{AccessToken: accessToken, Offset: offset, Limit: limit})
+}
 
-	var result GetPageListResponse
-	if err := c.doRequest("POST", "getPageList", body, &result); err != nil {
-		return nil, fmt.Errorf("failed to get page list: %w", err)
-	}
+// GetPageListWith retrieves a list of pages for a Telegraph account
+// See https://telegra.ph/api#getPageList
+func (c *Client) GetPageListWith(ctx context.Context, req GetPageListRequest) (*PageList, error) {
+	return call[PageList](ctx, c, "getPageList", req, ErrGetPageListFailed)
+}
 
-	if !result.Ok {
-		return nil, ErrGetPageListFailed
+// GetAllPages retrieves all pages of a Telegraph account, requesting
+// MaxPageListLimit pages at a time. On failure the pages retrieved so far are
+// returned along with the error.
+func (c *Client) GetAllPages(ctx context.Context, accessToken string) ([]Page, error) {
+	var pages []Page
+	for {
+		list, err := c.GetPageListWith(ctx, GetPageListRequest{
+			AccessToken: accessToken,
+			Offset:      len(pages),
+			Limit:       MaxPageListLimit,
+		})
+		if err != nil {
+			return pages, err
+		}
+		pages = append(pages, list.Pages...)
+		if len(list.Pages) == 0 || len(pages) >= list.TotalCount {
+			return pages, nil
+		}
 	}
+}
 
-	return &result.Result, nil
+// getAllPagesWithContent retrieves all pages of an account with their
+// content, which page lists do not include
+func (c *Client) getAllPagesWithContent(ctx context.Context, accessToken string) ([]Page, error) {
+	listed, err := c.GetAllPages(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	pages := make([]Page, 0, len(listed))
+	for _, entry := range listed {
+		page, err := c.GetPageWith(ctx, GetPageRequest{Path: entry.Path, ReturnContent: true})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s: %w", entry.Path, err)
+		}
+		page.Path = entry.Path
+		pages = append(pages, *page)
+	}
+	return pages, nil
 }
 
 // GetViews retrieves the number of views for a page on Telegraph
 // See https://telegra.ph/api#getViews
 func (c *Client) GetViews(path string, year, month, day int) (*PageViews, error) {
-	body := map[string]interface{}{
-		"path":  path,
-		"year":  year,
-		"month": month,
-		"day":   day,
-	}
-
-	var result GetViewsResponse
-	if err := c.doRequest("POST", "getViews", body, &result); err != nil {
-		return nil, fmt.Errorf("failed to get views: %w", err)
-	}
-
-	if !result.Ok {
-		return nil, ErrGetViewsFailed
-	}
+	return c.GetViewsWith(context.Background(), GetViewsRequest{Path: path, Year: year, Month: month, Day: day})
+}
 
-	return &result.Result, nil
+// GetViewsWith retrieves the number of views for a page on Telegraph
+// See https://telegra.ph/api#getViews
+func (c *Client) GetViewsWith(ctx context.Context, req GetViewsRequest) (*PageViews, error) {
+	return call[PageViews](ctx, c, "getViews", req, ErrGetViewsFailed)
 }
@@ -1,7 +1,12 @@
 package telegraph_test
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/smirnoffmg/telegraph"
@@ -149,6 +154,41 @@ func TestGetPageList(t *testing.T) {
 	}
 }
 
+func TestGetAllPages(t *testing.T) {
+	const total = 450
+	var offsets []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req telegraph.GetPageListRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+		offsets = append(offsets, req.Offset)
+
+		list := telegraph.PageList{TotalCount: total}
+		for i := req.Offset; i < total && i < req.Offset+req.Limit; i++ {
+			list.Pages = append(list.Pages, telegraph.Page{Path: fmt.Sprintf("page-%d", i)})
+		}
+		if err := json.NewEncoder(w).Encode(telegraph.Response[telegraph.PageList]{Ok: true, Result: list}); err != nil {
+			t.Errorf("Failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client := telegraph.NewClient(server.Client())
+	client.SetBaseURL(server.URL + "/")
+
+	pages, err := client.GetAllPages(context.Background(), accessToken)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(pages) != total || pages[total-1].Path != "page-449" {
+		t.Errorf("Expected %d pages ending with page-449, got %d", total, len(pages))
+	}
+	if want := []int{0, 200, 400}; fmt.Sprint(offsets) != fmt.Sprint(want) {
+		t.Errorf("Expected offsets %v, got %v", want, offsets)
+	}
+}
+
 func TestGetViews(t *testing.T) {
 	server := mockServer(testViewsResponse, http.StatusOK)
 	defer server.Close()
@@ -176,3 +216,112 @@ func TestGetViews(t *testing.T) {
 		t.Fatalf("Expected error, got nil")
 	}
 }
+
+func TestPatchPage(t *testing.T) {
+	server, requests := recordingServer(t, map[string]string{
+		"getPage":  testPageResponse,
+		"editPage": testPageResponse,
+	})
+
+	client := telegraph.NewClient(server.Client())
+	client.SetBaseURL(server.URL + "/")
+
+	_, err := client.PatchPage(accessToken, path, telegraph.PagePatch{AuthorName: telegraph.String("New Name")})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(*requests) != 2 || (*requests)[0].Method != "getPage" {
+		t.Fatalf("Expected page to be fetched before editing, got %v", *requests)
+	}
+	body := (*requests)[1].Body
+	if body["title"] != title || body["content"] == nil || body["author_name"] != "New Name" {
+		t.Errorf("Expected current title and content with new author_name, got %v", body)
+	}
+	if _, ok := body["author_url"]; ok {
+		t.Errorf("Expected author_url not to be sent, got %v", body)
+	}
+
+	// Nothing is fetched when title and content are given
+	*requests = nil
+	content := []telegraph.Node{telegraph.NodeElement{Tag: "p", Children: []telegraph.Node{"New"}}}
+	if _, err := client.PatchPage(accessToken, path, telegraph.PagePatch{Title: telegraph.String("New"), Content: content}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(*requests) != 1 {
+		t.Errorf("Expected a single edit request, got %v", *requests)
+	}
+
+	// Empty content is rejected rather than silently left out
+	*requests = nil
+	_, err = client.PatchPage(accessToken, path, telegraph.PagePatch{Content: []telegraph.Node{}})
+	if !errors.Is(err, telegraph.ErrEmptyContent) {
+		t.Errorf("Expected ErrEmptyContent, got %v", err)
+	}
+	if len(*requests) != 0 {
+		t.Errorf("Expected nothing to be sent, got %v", *requests)
+	}
+
+	// UpdatePage sends author fields only when they changed
+	*requests = nil
+	_, err = client.UpdatePage(accessToken, path, func(p *telegraph.Page) {
+		p.Title = "Updated"
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	body = (*requests)[1].Body
+	if body["title"] != "Updated" || body["author_name"] != nil || body["author_url"] != nil {
+		t.Errorf("Expected only title and content to be sent, got %v", body)
+	}
+}
+
+func TestPageRequests(t *testing.T) {
+	server, requests := recordingServer(t, map[string]string{
+		"createPage":  testPageResponse,
+		"getPageList": testPageListResponse,
+		"getViews":    testViewsResponse,
+	})
+
+	client := telegraph.NewClient(server.Client())
+	client.SetBaseURL(server.URL + "/")
+	ctx := context.Background()
+
+	content := []telegraph.Node{telegraph.NodeElement{Tag: "p", Children: []telegraph.Node{"Hello"}}}
+	_, err := client.CreatePageWith(ctx, telegraph.CreatePageRequest{
+		AccessToken:   accessToken,
+		Title:         title,
+		Content:       content,
+		ReturnContent: true,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	body := (*requests)[0].Body
+	if body["return_content"] != true || body["title"] != title {
+		t.Errorf("Expected title and return_content to be sent, got %v", body)
+	}
+	if _, ok := body["author_name"]; ok {
+		t.Errorf("Expected empty author_name not to be sent, got %v", body)
+	}
+
+	pageList, err := client.GetPageListWith(ctx, telegraph.GetPageListRequest{AccessToken: accessToken, Limit: 200})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if pageList.TotalCount != 1 {
+		t.Errorf("Expected TotalCount to be 1, got %d", pageList.TotalCount)
+	}
+	body = (*requests)[1].Body
+	if _, ok := body["offset"]; ok || body["limit"] != float64(200) {
+		t.Errorf("Expected only limit to be sent, got %v", body)
+	}
+
+	_, err = client.GetViewsWith(ctx, telegraph.GetViewsRequest{Path: path, Year: 2024, Month: 5, Day: 1, Hour: telegraph.Int(0)})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	body = (*requests)[2].Body
+	if body["hour"] != float64(0) || body["day"] != float64(1) {
+		t.Errorf("Expected day and hour 0 to be sent, got %v", body)
+	}
+}
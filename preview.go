@@ -0,0 +1,210 @@
+package telegraph
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Article is a page as shown by RenderArticle
+type Article struct {
+	Title      string
+	AuthorName string
+	AuthorURL  string
+	Date       time.Time // shown next to the author unless zero
+	Content    []Node
+}
+
+// previewData is the data of articleTemplate
+type previewData struct {
+	Article *Article
+	Body    template.HTML
+	Error   string
+	Version string // reload when the handler reports another version, if set
+}
+
+// articleTemplate renders an article as a page that looks like a Telegraph
+// page
+var articleTemplate = template.Must(template.New("article").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{with .Article}}{{.Title}}{{else}}Error{{end}}</title>
+<style>
+body { margin: 0; color: #000; background: #fff; font: 18px/1.58 Georgia, "Times New Roman", serif; }
+main { max-width: 732px; margin: 0 auto; padding: 21px 21px 60px; }
+h1, h3, h4, address { font-family: "Lucida Grande", Arial, Helvetica, sans-serif; }
+h1 { font-size: 32px; line-height: 1.3; margin: 21px 0 12px; }
+address { font-size: 15px; font-style: normal; color: #79828b; margin-bottom: 21px; }
+address a { color: #79828b; }
+h3 { font-size: 24px; line-height: 1.3; margin: 32px 0 12px; }
+h4 { font-size: 19px; line-height: 1.3; margin: 32px 0 12px; }
+p { margin: 0 0 12px; }
+a { color: #000; text-decoration: underline; }
+blockquote { margin: 0 0 12px; padding: 0 18px; border-left: 3px solid #000; font-style: italic; }
+aside { margin: 0 0 12px; text-align: center; font-style: italic; font-size: 24px; }
+pre { margin: 0 0 12px; padding: 8px 14px; background: #f5f5f5; border-radius: 4px; white-space: pre-wrap; font-size: 15px; }
+code { font-size: 15px; background: #f5f5f5; }
+pre code { background: none; }
+hr { width: 25%; margin: 32px auto; border: 0; border-top: 1px solid #000; }
+figure { margin: 0 0 16px; text-align: center; }
+figure img, figure video, img { max-width: 100%; }
+figure iframe { width: 100%; aspect-ratio: 16 / 9; border: 0; }
+figcaption { font-size: 15px; color: #79828b; padding: 8px 0 0; }
+.error { color: #c00; white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<main>
+{{with .Article}}<h1>{{.Title}}</h1>
+<address>{{if .AuthorURL}}<a href="{{.AuthorURL}}">{{or .AuthorName .AuthorURL}}</a>{{else}}{{.AuthorName}}{{end}}
+{{- if and (or .AuthorName .AuthorURL) (not .Date.IsZero)}} &bull; {{end}}{{if not .Date.IsZero}}{{.Date.Format "January 2, 2006"}}{{end}}</address>
+{{end}}{{with .Error}}<p class="error">{{.}}</p>
+{{end}}{{.Body}}
+</main>
+{{with .Version}}<script>
+setInterval(function () {
+  fetch(location.pathname + "?version").then(function (r) { return r.text(); }).then(function (v) {
+    if (v !== {{.}}) { location.reload(); }
+  }).catch(function () {});
+}, 1000);
+</script>
+{{end}}</body>
+</html>
+`))
+
+// RenderArticle writes article as a standalone HTML document styled like a
+// Telegraph page. Sources and links relative to Telegraph, such as uploaded
+// files and embeds, are made absolute so that they show.
+func RenderArticle(w io.Writer, article *Article) error {
+	return articleTemplate.Execute(w, &previewData{Article: article, Body: articleBody(article)})
+}
+
+// articleBody renders the content of article as HTML
+func articleBody(article *Article) template.HTML {
+	content := RewriteURLs(safeContent(article.Content), func(_, _, value string) string {
+		if strings.HasPrefix(value, "/") && !strings.HasPrefix(value, "//") {
+			return pageURLPrefix + strings.TrimPrefix(value, "/")
+		}
+		return value
+	})
+	// ContentToHTML escapes text and attribute values
+	return template.HTML(ContentToHTML(content))
+}
+
+// safeContent returns a copy of content that can be shown as trusted HTML:
+// elements Telegraph does not allow are replaced with their children, and
+// attributes other than href and src are dropped, as are URLs that are not
+// relative or use other schemes than http, https and mailto
+func safeContent(content []Node) []Node {
+	out := make([]Node, 0, len(content))
+	for _, n := range content {
+		if s, ok := n.(string); ok {
+			out = append(out, s)
+			continue
+		}
+		elem, ok := asElement(n)
+		if !ok {
+			continue
+		}
+		children := safeContent(elem.Children)
+		if _, ok := allowedTags[elem.Tag]; !ok {
+			out = append(out, children...)
+			continue
+		}
+
+		var attrs map[string]string
+		for key, value := range elem.Attrs {
+			if _, ok := allowedAttrs[key]; !ok || !safeURL(value) {
+				continue
+			}
+			if attrs == nil {
+				attrs = make(map[string]string)
+			}
+			attrs[key] = value
+		}
+		out = append(out, NodeElement{Tag: elem.Tag, Attrs: attrs, Children: children})
+	}
+	return out
+}
+
+// safeURL reports whether following the URL cannot run scripts
+func safeURL(value string) bool {
+	u, err := url.Parse(value)
+	if err != nil {
+		return false
+	}
+	switch u.Scheme {
+	case "", "http", "https", "mailto":
+		return true
+	}
+	return false
+}
+
+// PreviewHandler returns a handler showing articles to preview pages before
+// they are published. load returns the article for the path of a request;
+// errors wrapping fs.ErrNotExist are answered with 404 Not Found and other
+// errors are shown instead of the article. Pages check the handler every
+// second and reload themselves when their article changed, so edits to the
+// source of an article show right away.
+func PreviewHandler(load func(path string) (*Article, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		status := http.StatusOK
+		data := &previewData{}
+		article, err := load(r.URL.Path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			status = http.StatusNotFound
+			data.Error = err.Error()
+		case err != nil:
+			status = http.StatusInternalServerError
+			data.Error = err.Error()
+		default:
+			data.Article = article
+			data.Body = articleBody(article)
+		}
+		data.Version = previewVersion(data)
+
+		w.Header().Set("Cache-Control", "no-store")
+		if r.URL.Query().Has("version") {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			_, _ = io.WriteString(w, data.Version)
+			return
+		}
+
+		var b bytes.Buffer
+		if renderErr := articleTemplate.Execute(&b, data); renderErr != nil {
+			http.Error(w, renderErr.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(status)
+		_, _ = w.Write(b.Bytes())
+	})
+}
+
+// previewVersion returns a hash identifying what data shows
+func previewVersion(data *previewData) string {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:8])
+}
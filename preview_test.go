@@ -0,0 +1,147 @@
+package telegraph_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/smirnoffmg/telegraph"
+)
+
+func TestRenderArticle(t *testing.T) {
+	content, err := telegraph.MarkdownToContent("Some *text* & more.\n\n![A cat](cat.png)\n\n![Uploaded](/file/dog.png)\n\nhttps://www.youtube.com/watch?v=dQw4w9WgXcQ\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	err = telegraph.RenderArticle(&b, &telegraph.Article{
+		Title:      "Cats <3",
+		AuthorName: "Anonymous",
+		AuthorURL:  "https://example.com",
+		Date:       time.Date(2024, 10, 19, 0, 0, 0, 0, time.UTC),
+		Content:    content,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	page := b.String()
+	for _, want := range []string{
+		"<title>Cats &lt;3</title>",
+		"<h1>Cats &lt;3</h1>",
+		`<address><a href="https://example.com">Anonymous</a> &bull; October 19, 2024</address>`,
+		"<p>Some <em>text</em> &amp; more.</p>",
+		`<figure><img src="cat.png"><figcaption>A cat</figcaption></figure>`,
+		`<img src="https://telegra.ph/file/dog.png">`,
+		`<iframe src="https://telegra.ph/embed/youtube?url=`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("Expected page to contain %q, got:\n%s", want, page)
+		}
+	}
+	if strings.Contains(page, "<script>") {
+		t.Error("Expected no reload script in a rendered article")
+	}
+}
+
+func TestRenderArticleUnsafeContent(t *testing.T) {
+	tests := []struct {
+		name    string
+		content telegraph.Node
+		want    string
+	}{
+		{"script", telegraph.NodeElement{Tag: "script", Children: []telegraph.Node{"alert(1)"}}, "alert(1)"},
+		{"tag with attributes", telegraph.NodeElement{Tag: `p onclick="alert(1)"`, Children: []telegraph.Node{"text"}}, "text"},
+		{"event handler", telegraph.NodeElement{Tag: "p", Attrs: map[string]string{"onclick": "alert(1)"}, Children: []telegraph.Node{"text"}}, "<p>text</p>"},
+		{"javascript link", telegraph.NodeElement{Tag: "a", Attrs: map[string]string{"href": "JavaScript:alert(1)"}, Children: []telegraph.Node{"link"}}, "<a>link</a>"},
+		{"data image", telegraph.NodeElement{Tag: "img", Attrs: map[string]string{"src": "data:text/html,<script>"}}, "<img>"},
+		{"mail link", telegraph.NodeElement{Tag: "a", Attrs: map[string]string{"href": "mailto:a@example.com"}, Children: []telegraph.Node{"mail"}},
+			`<a href="mailto:a@example.com">mail</a>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := telegraph.RenderArticle(&b, &telegraph.Article{Title: "Unsafe", Content: []telegraph.Node{tt.content}}); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			body := b.String()
+			body = body[strings.Index(body, "</address>"):strings.Index(body, "</main>")]
+			if !strings.Contains(body, tt.want) {
+				t.Errorf("Expected %q in the body, got %s", tt.want, body)
+			}
+			for _, unsafe := range []string{"<script", "onclick", "alert(1)\"", "javascript:", "data:"} {
+				if strings.Contains(strings.ToLower(body), unsafe) {
+					t.Errorf("Expected no %q in the body, got %s", unsafe, body)
+				}
+			}
+		})
+	}
+}
+
+func TestPreviewHandler(t *testing.T) {
+	text := "First"
+	handler := telegraph.PreviewHandler(func(path string) (*telegraph.Article, error) {
+		switch path {
+		case "/":
+			return &telegraph.Article{Title: "Draft", Content: []telegraph.Node{telegraph.NodeElement{Tag: "p", Children: []telegraph.Node{text}}}}, nil
+		case "/broken":
+			return nil, errors.New("unclosed front matter")
+		}
+		return nil, fmt.Errorf("%s: %w", path, fs.ErrNotExist)
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	get := func(path string) (int, string) {
+		t.Helper()
+		resp, err := server.Client().Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode, string(body)
+	}
+
+	status, page := get("/")
+	if status != http.StatusOK || !strings.Contains(page, "<p>First</p>") || !strings.Contains(page, "<script>") {
+		t.Errorf("Expected the article with a reload script, got %d:\n%s", status, page)
+	}
+	_, version := get("/?version")
+	if !strings.Contains(page, `"`+version+`"`) {
+		t.Errorf("Expected version %q in the page", version)
+	}
+	if _, again := get("/?version"); again != version {
+		t.Errorf("Expected version %q to be stable, got %q", version, again)
+	}
+	text = "Second"
+	if _, changed := get("/?version"); changed == version {
+		t.Error("Expected version to change with the article")
+	}
+
+	if status, page := get("/broken"); status != http.StatusInternalServerError || !strings.Contains(page, "unclosed front matter") {
+		t.Errorf("Expected error shown with status 500, got %d:\n%s", status, page)
+	}
+	if status, _ := get("/missing.md"); status != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", status)
+	}
+
+	resp, err := server.Client().Post(server.URL+"/", "text/plain", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405, got %d", resp.StatusCode)
+	}
+}
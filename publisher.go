@@ -0,0 +1,218 @@
+package telegraph
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// PublishState records the page a document was published to
+type PublishState struct {
+	Path string `json:"path"`
+	URL  string `json:"url"`
+	Hash string `json:"hash"` // ContentHash of the published document
+}
+
+// StateStore persists publish states keyed by external document ID
+type StateStore interface {
+	// Get returns the state stored for id and whether there is one
+	Get(id string) (PublishState, bool, error)
+	// Put stores the state for id, replacing any previous state
+	Put(id string, state PublishState) error
+}
+
+// FileStateStore is a StateStore keeping states in a JSON file
+type FileStateStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// Document is a page identified by an external ID, such as a file path or
+// a record key, to be published with a Publisher
+type Document struct {
+	ID         string
+	Title      string
+	AuthorName string
+	AuthorURL  string
+	Content    []Node
+}
+
+// PublishAction tells what Publish did with a document
+type PublishAction int
+
+const (
+	// PublishCreated means a new page was created
+	PublishCreated PublishAction = iota
+	// PublishUpdated means the existing page was edited
+	PublishUpdated
+	// PublishUnchanged means the page was up to date and nothing was sent
+	PublishUnchanged
+)
+
+// String returns the name of the action
+func (a PublishAction) String() string {
+	switch a {
+	case PublishCreated:
+		return "created"
+	case PublishUpdated:
+		return "updated"
+	case PublishUnchanged:
+		return "unchanged"
+	}
+	return fmt.Sprintf("PublishAction(%d)", int(a))
+}
+
+// PublishResult describes the outcome of publishing a document
+type PublishResult struct {
+	Action PublishAction
+	// Page is the created or edited page. For unchanged documents only
+	// Path, URL and Title are set.
+	Page *Page
+}
+
+// Publisher publishes documents idempotently: the first publish of a
+// document creates a page, later ones edit that page only when the document
+// changed. Paths of published documents are kept in a StateStore.
+type Publisher struct {
+	client *AccountClient
+	store  StateStore
+}
+
+// NewFileStateStore creates a state store kept in a JSON file at path
+func NewFileStateStore(path string) *FileStateStore {
+	return &FileStateStore{path: path}
+}
+
+// Get returns the state stored for id
+func (s *FileStateStore) Get(id string) (PublishState, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	states, err := s.read()
+	if err != nil {
+		return PublishState{}, false, err
+	}
+	state, ok := states[id]
+	return state, ok, nil
+}
+
+// Put stores the state for id. The file is replaced atomically.
+func (s *FileStateStore) Put(id string, state PublishState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	states, err := s.read()
+	if err != nil {
+		return err
+	}
+	states[id] = state
+
+	data, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state store: %w", err)
+	}
+	return WriteFileAtomic(s.path, data)
+}
+
+// read loads all states, treating a missing file as an empty store
+func (s *FileStateStore) read() (map[string]PublishState, error) {
+	states := make(map[string]PublishState)
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return states, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state store: %w", err)
+	}
+	if err := json.Unmarshal(data, &states); err != nil {
+		return nil, fmt.Errorf("failed to decode state store: %w", err)
+	}
+	return states, nil
+}
+
+// NewPublisher creates a publisher creating pages with client and keeping
+// their paths in store
+func NewPublisher(client *AccountClient, store StateStore) *Publisher {
+	return &Publisher{client: client, store: store}
+}
+
+// Publish creates the page for doc on its first publish and afterwards edits
+// that page if the ContentHash of doc changed since the last publish.
+//
+// If the page was published but its state could not be stored, the result
+// is returned with an error wrapping ErrStateNotSaved. The caller should
+// record the path of the page, or the next publish creates another one.
+func (p *Publisher) Publish(ctx context.Context, doc Document) (*PublishResult, error) {
+	if doc.ID == "" {
+		return nil, ErrNoDocumentID
+	}
+
+	hash, err := ContentHash(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	state, ok, err := p.store.Get(doc.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load state of %s: %w", doc.ID, err)
+	}
+
+	if ok && state.Hash == hash {
+		return &PublishResult{
+			Action: PublishUnchanged,
+			Page:   &Page{Path: state.Path, URL: state.URL, Title: doc.Title},
+		}, nil
+	}
+
+	var page *Page
+	action := PublishCreated
+	if ok {
+		action = PublishUpdated
+		page, err = p.client.EditPageWith(ctx, EditPageRequest{
+			Path:       state.Path,
+			Title:      doc.Title,
+			Content:    doc.Content,
+			AuthorName: String(doc.AuthorName),
+			AuthorURL:  String(doc.AuthorURL),
+		})
+	} else {
+		page, err = p.client.CreatePageWith(ctx, CreatePageRequest{
+			Title:      doc.Title,
+			Content:    doc.Content,
+			AuthorName: doc.AuthorName,
+			AuthorURL:  doc.AuthorURL,
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	state = PublishState{Path: page.Path, URL: page.URL, Hash: hash}
+	result := &PublishResult{Action: action, Page: page}
+	if err := p.store.Put(doc.ID, state); err != nil {
+		return result, fmt.Errorf("%w for %s published to %s: %w", ErrStateNotSaved, doc.ID, page.Path, err)
+	}
+	return result, nil
+}
+
+// ContentHash returns a hash of the title, author and content of doc.
+// Documents with equal hashes render as the same page.
+func ContentHash(doc Document) (string, error) {
+	data, err := json.Marshal(struct {
+		Title      string `json:"title"`
+		AuthorName string `json:"author_name"`
+		AuthorURL  string `json:"author_url"`
+		Content    []Node `json:"content"`
+	}{doc.Title, doc.AuthorName, doc.AuthorURL, doc.Content})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode document: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,105 @@
+package telegraph_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/smirnoffmg/telegraph"
+)
+
+func TestPublisher(t *testing.T) {
+	server, requests := recordingServer(t, map[string]string{
+		"createPage": testPageResponse,
+		"editPage":   testPageResponse,
+	})
+
+	client := telegraph.NewClient(server.Client())
+	client.SetBaseURL(server.URL + "/")
+
+	store := telegraph.NewFileStateStore(filepath.Join(t.TempDir(), "state.json"))
+	publisher := telegraph.NewPublisher(client.WithToken(accessToken), store)
+
+	doc := telegraph.Document{
+		ID:      "docs/intro.md",
+		Title:   title,
+		Content: []telegraph.Node{telegraph.NodeElement{Tag: "p", Children: []telegraph.Node{"Hello"}}},
+	}
+
+	steps := []struct {
+		name   string
+		update func(*telegraph.Document)
+		want   telegraph.PublishAction
+		method string
+	}{
+		{"First publish creates", func(*telegraph.Document) {}, telegraph.PublishCreated, "createPage"},
+		{"Same content is skipped", func(*telegraph.Document) {}, telegraph.PublishUnchanged, ""},
+		{"Changed content is edited", func(d *telegraph.Document) {
+			d.Content = []telegraph.Node{telegraph.NodeElement{Tag: "p", Children: []telegraph.Node{"Bye"}}}
+		}, telegraph.PublishUpdated, "editPage"},
+	}
+
+	for _, step := range steps {
+		*requests = nil
+		step.update(&doc)
+
+		result, err := publisher.Publish(context.Background(), doc)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", step.name, err)
+		}
+		if result.Action != step.want {
+			t.Errorf("%s: expected %s, got %s", step.name, step.want, result.Action)
+		}
+		if result.Page.Path != path {
+			t.Errorf("%s: expected path '%s', got '%s'", step.name, path, result.Page.Path)
+		}
+
+		switch {
+		case step.method == "" && len(*requests) != 0:
+			t.Errorf("%s: expected no requests, got %v", step.name, *requests)
+		case step.method != "" && (len(*requests) != 1 || (*requests)[0].Method != step.method):
+			t.Errorf("%s: expected a %s request, got %v", step.name, step.method, *requests)
+		}
+	}
+
+	state, ok, err := store.Get(doc.ID)
+	if err != nil || !ok {
+		t.Fatalf("Expected stored state, got %v (%v)", ok, err)
+	}
+	hash, err := telegraph.ContentHash(doc)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if state.Path != path || state.Hash != hash {
+		t.Errorf("Expected state for '%s' with the current hash, got %+v", path, state)
+	}
+}
+
+// failingStore is a StateStore that cannot save states
+type failingStore struct{}
+
+func (failingStore) Get(string) (telegraph.PublishState, bool, error) {
+	return telegraph.PublishState{}, false, nil
+}
+
+func (failingStore) Put(string, telegraph.PublishState) error {
+	return errors.New("disk full")
+}
+
+func TestPublisherStoreFailure(t *testing.T) {
+	server, _ := recordingServer(t, map[string]string{"createPage": testPageResponse})
+
+	client := telegraph.NewClient(server.Client())
+	client.SetBaseURL(server.URL + "/")
+	publisher := telegraph.NewPublisher(client.WithToken(accessToken), failingStore{})
+
+	result, err := publisher.Publish(context.Background(), telegraph.Document{ID: "doc", Title: title, Content: []telegraph.Node{"Hello"}})
+	if !errors.Is(err, telegraph.ErrStateNotSaved) {
+		t.Fatalf("Expected ErrStateNotSaved, got %v", err)
+	}
+	// The created page is returned so that its path is not lost
+	if result == nil || result.Action != telegraph.PublishCreated || result.Page.Path != path {
+		t.Errorf("Expected the created page '%s', got %+v", path, result)
+	}
+}
@@ -0,0 +1,82 @@
+package telegraph
+
+// CreateAccountRequest holds the parameters of the createAccount method
+// See https://telegra.ph/api#createAccount
+type CreateAccountRequest struct {
+	ShortName  string `json:"short_name"`
+	AuthorName string `json:"author_name,omitempty"`
+	AuthorURL  string `json:"author_url,omitempty"`
+}
+
+// GetAccountInfoRequest holds the parameters of the getAccountInfo method
+// See https://telegra.ph/api#getAccountInfo
+type GetAccountInfoRequest struct {
+	AccessToken string         `json:"access_token"`
+	Fields      []AccountField `json:"fields,omitempty"`
+}
+
+// EditAccountInfoRequest holds the parameters of the editAccountInfo method.
+// Nil fields of the patch are not sent and keep their current values.
+// See https://telegra.ph/api#editAccountInfo
+type EditAccountInfoRequest struct {
+	AccessToken string `json:"access_token"`
+	AccountPatch
+}
+
+// RevokeAccessTokenRequest holds the parameters of the revokeAccessToken method
+// See https://telegra.ph/api#revokeAccessToken
+type RevokeAccessTokenRequest struct {
+	AccessToken string `json:"access_token"`
+}
+
+// CreatePageRequest holds the parameters of the createPage method
+// See https://telegra.ph/api#createPage
+type CreatePageRequest struct {
+	AccessToken   string `json:"access_token"`
+	Title         string `json:"title"`
+	AuthorName    string `json:"author_name,omitempty"`
+	AuthorURL     string `json:"author_url,omitempty"`
+	Content       []Node `json:"content"`
+	ReturnContent bool   `json:"return_content,omitempty"`
+}
+
+// EditPageRequest holds the parameters of the editPage method.
+// Nil author fields are not sent and keep their current values.
+// See https://telegra.ph/api#editPage
+type EditPageRequest struct {
+	AccessToken   string  `json:"access_token"`
+	Path          string  `json:"path"`
+	Title         string  `json:"title"`
+	Content       []Node  `json:"content"`
+	AuthorName    *string `json:"author_name,omitempty"`
+	AuthorURL     *string `json:"author_url,omitempty"`
+	ReturnContent bool    `json:"return_content,omitempty"`
+}
+
+// GetPageRequest holds the parameters of the getPage method
+// See https://telegra.ph/api#getPage
+type GetPageRequest struct {
+	Path          string `json:"path"`
+	ReturnContent bool   `json:"return_content,omitempty"`
+}
+
+// GetPageListRequest holds the parameters of the getPageList method.
+// Zero Offset and Limit use the API defaults of 0 and 50.
+// See https://telegra.ph/api#getPageList
+type GetPageListRequest struct {
+	AccessToken string `json:"access_token"`
+	Offset      int    `json:"offset,omitempty"`
+	Limit       int    `json:"limit,omitempty"`
+}
+
+// GetViewsRequest holds the parameters of the getViews method. Zero date
+// fields and a nil Hour are not sent, so views are counted for the whole
+// period that is set, or for all time.
+// See https://telegra.ph/api#getViews
+type GetViewsRequest struct {
+	Path  string `json:"path"`
+	Year  int    `json:"year,omitempty"`
+	Month int    `json:"month,omitempty"`
+	Day   int    `json:"day,omitempty"`
+	Hour  *int   `json:"hour,omitempty"`
+}
@@ -0,0 +1,220 @@
+package telegraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+)
+
+// MaxContentSize is the largest content, in bytes of JSON, Telegraph accepts for a page
+const MaxContentSize = 64 * 1024
+
+// MaxTitleLength is the longest page title, in characters, Telegraph accepts
+const MaxTitleLength = 256
+
+// seriesReserve is the space kept free in every part for the header and navigation
+const seriesReserve = 1024
+
+// SplitContent splits content into chunks whose JSON encoding fits into limit
+// bytes. Content is split between top-level blocks; blocks that are too large
+// on their own are split between their children or, for text, between words.
+func SplitContent(content []Node, limit int) ([][]Node, error) {
+	var chunks [][]Node
+	var chunk []Node
+	size := len("[]")
+
+	for _, n := range content {
+		parts, err := splitNode(n, limit-len("[]"))
+		if err != nil {
+			return nil, err
+		}
+		for _, part := range parts {
+			partSize := nodeSize(part)
+			if len(chunk) > 0 && size+len(",")+partSize > limit {
+				chunks = append(chunks, chunk)
+				chunk, size = nil, len("[]")
+			}
+			if len(chunk) > 0 {
+				size += len(",")
+			}
+			chunk = append(chunk, part)
+			size += partSize
+		}
+	}
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks, nil
+}
+
+// splitNode splits n into nodes that are at most limit bytes of JSON each
+func splitNode(n Node, limit int) ([]Node, error) {
+	if nodeSize(n) <= limit {
+		return []Node{n}, nil
+	}
+
+	if s, ok := n.(string); ok {
+		return splitText(s, limit)
+	}
+
+	elem, ok := asElement(n)
+	if !ok {
+		return nil, ErrContentTooLarge
+	}
+
+	// Room left for the children once the element itself is accounted for
+	shell := NodeElement{Tag: elem.Tag, Attrs: elem.Attrs, Children: []Node{""}}
+	room := limit - nodeSize(shell) + len(`""`)
+	if room <= 0 {
+		return nil, ErrContentTooLarge
+	}
+
+	var children []Node
+	for _, child := range elem.Children {
+		parts, err := splitNode(child, room)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, parts...)
+	}
+
+	groups, err := SplitContent(children, room+len("[]"))
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]Node, 0, len(groups))
+	for _, group := range groups {
+		nodes = append(nodes, NodeElement{Tag: elem.Tag, Attrs: elem.Attrs, Children: group})
+	}
+	return nodes, nil
+}
+
+// splitText splits s into pieces that are at most limit bytes of JSON each,
+// preferring to break after a space
+func splitText(s string, limit int) ([]Node, error) {
+	var pieces []Node
+	for s != "" {
+		end, lastSpace, size := 0, 0, len(`""`)
+		for end < len(s) {
+			r, width := utf8.DecodeRuneInString(s[end:])
+			runeSize := nodeSize(string(r)) - len(`""`)
+			if size+runeSize > limit {
+				break
+			}
+			size += runeSize
+			end += width
+			if r == ' ' {
+				lastSpace = end
+			}
+		}
+		if end == 0 {
+			return nil, ErrContentTooLarge
+		}
+		if end < len(s) && lastSpace > 0 {
+			end = lastSpace
+		}
+		pieces = append(pieces, s[:end])
+		s = s[end:]
+	}
+	return pieces, nil
+}
+
+// ContentSize returns the length of the JSON encoding of content, which
+// Telegraph limits to MaxContentSize bytes
+func ContentSize(content []Node) int {
+	return nodeSize(content)
+}
+
+// nodeSize returns the length of the JSON encoding of n
+func nodeSize(n Node) int {
+	data, err := json.Marshal(n)
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
+
+// PublishSeries publishes content that may exceed MaxContentSize as a series
+// of pages. Content that fits is published as a single page. Otherwise every
+// part gets a "Part N of M" header and links to the previous and next parts;
+// earlier parts are edited to link forward once the later ones are created.
+// On failure the pages created so far are returned along with the error.
+func (c *Client) PublishSeries(accessToken, title string, content []Node, authorName, authorURL string) ([]*Page, error) {
+	if nodeSize(content) <= MaxContentSize {
+		page, err := c.CreatePage(accessToken, title, content, authorName, authorURL)
+		if err != nil {
+			return nil, err
+		}
+		return []*Page{page}, nil
+	}
+
+	chunks, err := SplitContent(content, MaxContentSize-seriesReserve)
+	if err != nil {
+		return nil, fmt.Errorf("failed to split content: %w", err)
+	}
+
+	total := len(chunks)
+	pages := make([]*Page, 0, total)
+	for i, chunk := range chunks {
+		prevURL := ""
+		if i > 0 {
+			prevURL = pages[i-1].URL
+		}
+		page, err := c.CreatePage(accessToken, seriesTitle(title, i, total), seriesPart(chunk, i, total, prevURL, ""), authorName, authorURL)
+		if err != nil {
+			return pages, fmt.Errorf("failed to publish part %d of %d: %w", i+1, total, err)
+		}
+		pages = append(pages, page)
+	}
+
+	for i := 0; i < total-1; i++ {
+		prevURL := ""
+		if i > 0 {
+			prevURL = pages[i-1].URL
+		}
+		part := seriesPart(chunks[i], i, total, prevURL, pages[i+1].URL)
+		page, err := c.EditPage(accessToken, pages[i].Path, seriesTitle(title, i, total), part, authorName, authorURL)
+		if err != nil {
+			return pages, fmt.Errorf("failed to link part %d of %d: %w", i+1, total, err)
+		}
+		pages[i] = page
+	}
+
+	return pages, nil
+}
+
+// seriesTitle returns the title of the i-th part of a series. Titles too
+// long to take the part number are shortened to end in an ellipsis.
+func seriesTitle(title string, i, total int) string {
+	suffix := fmt.Sprintf(" (Part %d of %d)", i+1, total)
+	if room := MaxTitleLength - utf8.RuneCountInString(suffix); utf8.RuneCountInString(title) > room {
+		title = string([]rune(title)[:room-1]) + "…"
+	}
+	return title + suffix
+}
+
+// seriesPart decorates a chunk of content with the series header and navigation
+func seriesPart(chunk []Node, i, total int, prevURL, nextURL string) []Node {
+	part := make([]Node, 0, len(chunk)+2)
+	part = append(part, NodeElement{
+		Tag:      "p",
+		Children: []Node{NodeElement{Tag: "em", Children: []Node{fmt.Sprintf("Part %d of %d", i+1, total)}}},
+	})
+	part = append(part, chunk...)
+
+	var nav []Node
+	if prevURL != "" {
+		nav = append(nav, NodeElement{Tag: "a", Attrs: map[string]string{"href": prevURL}, Children: []Node{"← Previous part"}})
+	}
+	if nextURL != "" {
+		if len(nav) > 0 {
+			nav = append(nav, " | ")
+		}
+		nav = append(nav, NodeElement{Tag: "a", Attrs: map[string]string{"href": nextURL}, Children: []Node{"Next part →"}})
+	}
+	if len(nav) > 0 {
+		part = append(part, NodeElement{Tag: "p", Children: nav})
+	}
+	return part
+}
@@ -0,0 +1,128 @@
+package telegraph_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/smirnoffmg/telegraph"
+)
+
+func TestSplitContent(t *testing.T) {
+	content := []telegraph.Node{
+		telegraph.NodeElement{Tag: "p", Children: []telegraph.Node{strings.Repeat("a", 40)}},
+		telegraph.NodeElement{Tag: "p", Children: []telegraph.Node{strings.Repeat("b", 40)}},
+		telegraph.NodeElement{Tag: "ul", Children: []telegraph.Node{
+			telegraph.NodeElement{Tag: "li", Children: []telegraph.Node{strings.Repeat("c", 40)}},
+			telegraph.NodeElement{Tag: "li", Children: []telegraph.Node{strings.Repeat("d", 40)}},
+		}},
+		telegraph.NodeElement{Tag: "p", Children: []telegraph.Node{strings.Repeat("word ", 30)}},
+	}
+
+	const limit = 100
+	chunks, err := telegraph.SplitContent(content, limit)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(chunks) < 6 {
+		t.Fatalf("Expected content to be split into at least 6 chunks, got %d", len(chunks))
+	}
+	for i, chunk := range chunks {
+		data, err := json.Marshal(chunk)
+		if err != nil {
+			t.Fatalf("Failed to marshal chunk: %v", err)
+		}
+		if len(data) > limit {
+			t.Errorf("Expected chunk %d to fit into %d bytes, got %d: %s", i, limit, len(data), data)
+		}
+	}
+
+	_, err = telegraph.SplitContent([]telegraph.Node{
+		telegraph.NodeElement{Tag: "a", Attrs: map[string]string{"href": strings.Repeat("x", limit)}},
+	}, limit)
+	if !errors.Is(err, telegraph.ErrContentTooLarge) {
+		t.Errorf("Expected ErrContentTooLarge, got %v", err)
+	}
+}
+
+func TestPublishSeries(t *testing.T) {
+	var created, edited []map[string]interface{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		path := fmt.Sprintf("part-%d", len(created)+1)
+		if strings.HasPrefix(r.URL.Path, "/editPage/") {
+			edited = append(edited, body)
+			path = body["path"].(string)
+		} else {
+			created = append(created, body)
+		}
+
+		page := telegraph.Page{Path: path, URL: "https://telegra.ph/" + path, Title: body["title"].(string)}
+		if err := json.NewEncoder(w).Encode(telegraph.CreatePageResponse{Ok: true, Result: page}); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := telegraph.NewClient(server.Client())
+	client.SetBaseURL(server.URL + "/")
+
+	var content []telegraph.Node
+	for i := 0; i < 300; i++ {
+		content = append(content, telegraph.NodeElement{Tag: "p", Children: []telegraph.Node{strings.Repeat("x", 500)}})
+	}
+
+	pages, err := client.PublishSeries(accessToken, title, content, authorName, authorURL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(pages) != 3 || len(created) != 3 || len(edited) != 2 {
+		t.Fatalf("Expected 3 pages created and 2 edited, got %d pages, %d created, %d edited", len(pages), len(created), len(edited))
+	}
+	if pages[0].Title != "Test Page (Part 1 of 3)" {
+		t.Errorf("Expected part title, got '%s'", pages[0].Title)
+	}
+
+	first, err := json.Marshal(edited[0]["content"])
+	if err != nil {
+		t.Fatalf("Failed to marshal content: %v", err)
+	}
+	if !strings.Contains(string(first), "Part 1 of 3") || !strings.Contains(string(first), "https://telegra.ph/part-2") {
+		t.Errorf("Expected first part to have a header and a link to the next part")
+	}
+	if len(first) > telegraph.MaxContentSize {
+		t.Errorf("Expected part to fit into %d bytes, got %d", telegraph.MaxContentSize, len(first))
+	}
+
+	last, err := json.Marshal(created[2]["content"])
+	if err != nil {
+		t.Fatalf("Failed to marshal content: %v", err)
+	}
+	if !strings.Contains(string(last), "https://telegra.ph/part-2") || strings.Contains(string(last), "Next part") {
+		t.Errorf("Expected last part to link back only")
+	}
+
+	// Long titles are shortened to leave room for the part number
+	created = nil
+	if _, err := client.PublishSeries(accessToken, strings.Repeat("é", 300), content, authorName, authorURL); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for i, body := range created {
+		got := body["title"].(string)
+		if utf8.RuneCountInString(got) != telegraph.MaxTitleLength || !strings.HasSuffix(got, fmt.Sprintf("é… (Part %d of 3)", i+1)) {
+			t.Errorf("Expected a title of %d characters ending in the part number, got %q", telegraph.MaxTitleLength, got)
+		}
+	}
+}
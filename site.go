@@ -0,0 +1,282 @@
+package telegraph
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Files of an exported site
+const (
+	siteIndexFile = "index.html"
+	siteFeedFile  = "feed.xml"
+)
+
+// defaultSiteTitle is the title of sites without one
+const defaultSiteTitle = "Telegraph"
+
+// pageDatePattern matches the month and day Telegraph puts at the end of
+// paths, as in Hello-10-19 or Hello-10-19-2
+var pageDatePattern = regexp.MustCompile(`-(\d{2})-(\d{2})(?:-\d+)?$`)
+
+// SiteOptions controls ExportSite
+type SiteOptions struct {
+	// Title is the title of the index and the feed, "Telegraph" when empty
+	Title string
+	// BaseURL is the URL the site is served from. The feed links to the
+	// exported pages there, or to the original pages without it.
+	BaseURL string
+}
+
+// SitePage is a page of an exported site
+type SitePage struct {
+	Path  string    // path of the original page
+	Title string    // title of the page
+	Date  time.Time // creation date of the page, zero if unknown
+	File  string    // name of the HTML file in the site
+}
+
+// ExportSite writes a static website mirroring all pages of the account to
+// dir and returns its pages, newest first. Every page is rendered to an
+// HTML file like RenderArticle does, next to an index.html listing the
+// pages and a feed.xml Atom feed. Files uploaded to Telegraph are
+// downloaded to dir/assets and links between the pages are rewritten to
+// their files, so the site works without Telegraph.
+//
+// Telegraph does not report when pages were created, so their dates are
+// taken from their paths, which end with the month and day, counting years
+// back from the current one in the order the pages were created.
+func (c *Client) ExportSite(ctx context.Context, accessToken, dir string, opts SiteOptions) ([]SitePage, error) {
+	if opts.Title == "" {
+		opts.Title = defaultSiteTitle
+	}
+	if opts.BaseURL != "" && !strings.HasSuffix(opts.BaseURL, "/") {
+		opts.BaseURL += "/"
+	}
+
+	pages, err := c.getAllPagesWithContent(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	dates := pageDates(pages, time.Now())
+	site := make([]SitePage, len(pages))
+	files := make(map[string]string, len(pages))
+	for i, page := range pages {
+		site[i] = SitePage{Path: page.Path, Title: page.Title, Date: dates[i], File: strings.ReplaceAll(page.Path, "/", "-") + ".html"}
+		files[page.Path] = site[i].File
+	}
+
+	assets := make(map[string]string)
+	contents := make([][]Node, len(pages))
+	for i, page := range pages {
+		for _, src := range mediaSources(page.Content) {
+			if _, ok := assets[src]; ok {
+				continue
+			}
+			file, err := c.downloadAsset(ctx, dir, src)
+			if err != nil {
+				return nil, fmt.Errorf("failed to export %s: %w", page.Path, err)
+			}
+			if file != "" {
+				assets[src] = file
+			}
+		}
+		contents[i] = siteContent(page.Content, assets, files)
+
+		// Pages end with a link back to the index
+		content := append(append([]Node{}, contents[i]...), NodeElement{Tag: "hr"}, NodeElement{Tag: "p", Children: []Node{
+			NodeElement{Tag: "a", Attrs: map[string]string{"href": siteIndexFile}, Children: []Node{opts.Title}},
+		}})
+		article := &Article{Title: page.Title, AuthorName: page.AuthorName, AuthorURL: page.AuthorURL, Date: dates[i], Content: content}
+		if err := writeArticle(filepath.Join(dir, site[i].File), article); err != nil {
+			return nil, err
+		}
+	}
+
+	// Pages are listed newest first, and those without a date last
+	order := make([]int, len(pages))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return site[order[i]].Date.After(site[order[j]].Date) })
+	sorted := make([]SitePage, len(order))
+	for i, k := range order {
+		sorted[i] = site[k]
+	}
+
+	if err := writeArticle(filepath.Join(dir, siteIndexFile), siteIndex(opts.Title, sorted)); err != nil {
+		return nil, err
+	}
+	feed, err := siteFeed(opts, pages, site, contents, order)
+	if err != nil {
+		return nil, err
+	}
+	if err := WriteFileAtomic(filepath.Join(dir, siteFeedFile), feed); err != nil {
+		return nil, err
+	}
+	return sorted, nil
+}
+
+// siteContent returns content with the sources of downloaded files and the
+// links to exported pages rewritten to their files in the site
+func siteContent(content []Node, assets, files map[string]string) []Node {
+	return RewriteURLs(content, func(_, attr, value string) string {
+		if file, ok := assets[value]; ok && attr == "src" {
+			return file
+		}
+		if p, ok := telegraphPagePath(value); ok && attr == "href" {
+			if file, ok := files[p]; ok {
+				if u, err := url.Parse(value); err == nil && u.Fragment != "" {
+					return url.PathEscape(file) + "#" + u.Fragment
+				}
+				return url.PathEscape(file)
+			}
+		}
+		return value
+	})
+}
+
+// siteIndex returns the index article linking to pages
+func siteIndex(title string, pages []SitePage) *Article {
+	items := make([]Node, 0, len(pages))
+	for _, page := range pages {
+		children := []Node{NodeElement{Tag: "a", Attrs: map[string]string{"href": url.PathEscape(page.File)}, Children: []Node{page.Title}}}
+		if !page.Date.IsZero() {
+			children = append(children, " · "+page.Date.Format("January 2, 2006"))
+		}
+		items = append(items, NodeElement{Tag: "li", Children: children})
+	}
+	return &Article{Title: title, Content: []Node{NodeElement{Tag: "ul", Children: items}}}
+}
+
+// writeArticle renders article to the file at path
+func writeArticle(path string, article *Article) error {
+	var b bytes.Buffer
+	if err := RenderArticle(&b, article); err != nil {
+		return fmt.Errorf("failed to render %s: %w", article.Title, err)
+	}
+	return WriteFileAtomic(path, b.Bytes())
+}
+
+// pageDates returns the creation dates of pages listed newest first, as
+// getPageList lists them. Paths only tell the month and day, so the year
+// starts at the one of now and goes back whenever a page would be younger
+// than the page created after it. Pages without a date in their path get
+// the zero time.
+func pageDates(pages []Page, now time.Time) []time.Time {
+	dates := make([]time.Time, len(pages))
+	year, next := now.Year(), now
+	for i, page := range pages {
+		m := pageDatePattern.FindStringSubmatch(page.Path)
+		if m == nil {
+			continue
+		}
+		month, _ := strconv.Atoi(m[1])
+		day, _ := strconv.Atoi(m[2])
+		if month < 1 || month > 12 || day < 1 || day > 31 {
+			continue
+		}
+		date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+		if date.After(next) {
+			year--
+			date = time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+		}
+		dates[i], next = date, date
+	}
+	return dates
+}
+
+// Elements of an Atom feed
+type (
+	atomFeed struct {
+		XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+		Title   string      `xml:"title"`
+		ID      string      `xml:"id"`
+		Updated string      `xml:"updated"`
+		Author  atomAuthor  `xml:"author"`
+		Links   []atomLink  `xml:"link"`
+		Entries []atomEntry `xml:"entry"`
+	}
+	atomLink struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr,omitempty"`
+	}
+	atomEntry struct {
+		Title   string      `xml:"title"`
+		ID      string      `xml:"id"`
+		Updated string      `xml:"updated"`
+		Link    atomLink    `xml:"link"`
+		Author  *atomAuthor `xml:"author,omitempty"`
+		Content atomContent `xml:"content"`
+	}
+	atomAuthor struct {
+		Name string `xml:"name"`
+		URI  string `xml:"uri,omitempty"`
+	}
+	atomContent struct {
+		Type string `xml:"type,attr"`
+		Body string `xml:",chardata"`
+	}
+)
+
+// siteFeed returns the Atom feed of the pages of a site in order. Entries
+// link to the exported pages at the base URL, if there is one, and to the
+// original pages otherwise. Atom requires an author for every entry, so the
+// feed is authored by the site for entries of pages without one.
+func siteFeed(opts SiteOptions, pages []Page, site []SitePage, contents [][]Node, order []int) ([]byte, error) {
+	feed := atomFeed{Title: opts.Title, ID: "urn:telegraph:" + url.PathEscape(opts.Title), Author: atomAuthor{Name: opts.Title}}
+	if opts.BaseURL != "" {
+		feed.ID = opts.BaseURL
+		feed.Links = []atomLink{{Href: opts.BaseURL}, {Href: opts.BaseURL + siteFeedFile, Rel: "self"}}
+	}
+
+	var updated time.Time
+	for _, i := range order {
+		page, date := pages[i], site[i].Date
+		if date.After(updated) {
+			updated = date
+		}
+		if date.IsZero() {
+			date = updated
+		}
+
+		// Feed readers need absolute URLs
+		link, content := page.URL, page.Content
+		if opts.BaseURL != "" {
+			link = opts.BaseURL + url.PathEscape(site[i].File)
+			content = RewriteURLs(contents[i], func(_, _, value string) string {
+				if u, err := url.Parse(value); err == nil && !u.IsAbs() && !strings.HasPrefix(value, "/") && !strings.HasPrefix(value, "#") {
+					return opts.BaseURL + value
+				}
+				return value
+			})
+		}
+
+		entry := atomEntry{
+			Title:   page.Title,
+			ID:      page.URL,
+			Updated: date.Format(time.RFC3339),
+			Link:    atomLink{Href: link},
+			Content: atomContent{Type: "html", Body: string(articleBody(&Article{Content: content}))},
+		}
+		if page.AuthorName != "" {
+			entry.Author = &atomAuthor{Name: page.AuthorName, URI: page.AuthorURL}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	feed.Updated = updated.Format(time.RFC3339)
+
+	data, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode feed: %w", err)
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
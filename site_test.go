@@ -0,0 +1,102 @@
+package telegraph_test
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/smirnoffmg/telegraph"
+)
+
+func TestExportSite(t *testing.T) {
+	api := newMigrateServer()
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	client := telegraph.NewClient(server.Client())
+	client.SetBaseURL(server.URL + "/")
+	client.SetUploadURL(server.URL + "/upload")
+
+	dir := t.TempDir()
+	pages, err := client.WithToken("old").ExportSite(context.Background(), dir, telegraph.SiteOptions{
+		Title:   "My Blog",
+		BaseURL: "https://blog.example.com",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(pages) != 2 || pages[0].File != "Second-10-19.html" || pages[1].File != "First-10-18.html" {
+		t.Fatalf("Expected pages newest first, got %+v", pages)
+	}
+	if pages[0].Date.Month() != 10 || pages[0].Date.Day() != 19 || !pages[0].Date.After(pages[1].Date) {
+		t.Errorf("Expected dates from the paths, got %v and %v", pages[0].Date, pages[1].Date)
+	}
+
+	read := func(name string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("Expected %s to be written, got %v", name, err)
+		}
+		return string(data)
+	}
+
+	tests := []struct {
+		file string
+		want []string
+	}{
+		{"Second-10-19.html", []string{`<h1>Second</h1>`, `href="First-10-18.html#intro"`, `src="assets/cat.png"`, `href="index.html"`}},
+		{"First-10-18.html", []string{`<h1>First</h1>`, `href="Second-10-19.html"`, `href="https://example.com/"`, `Anonymous`}},
+		{"index.html", []string{`<h1>My Blog</h1>`, `href="Second-10-19.html">Second</a> · October 19`}},
+	}
+	for _, tt := range tests {
+		got := read(tt.file)
+		for _, want := range tt.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("Expected %s to contain %s, got %s", tt.file, want, got)
+			}
+		}
+	}
+	if index := read("index.html"); strings.Index(index, "Second-10-19.html") > strings.Index(index, "First-10-18.html") {
+		t.Errorf("Expected the index to list the newest page first, got %s", index)
+	}
+	if got := read("assets/cat.png"); !strings.HasPrefix(got, "\x89PNG") {
+		t.Errorf("Expected the image to be downloaded, got %q", got)
+	}
+
+	var feed struct {
+		Title  string `xml:"title"`
+		ID     string `xml:"id"`
+		Author struct {
+			Name string `xml:"name"`
+		} `xml:"author"`
+		Entries []struct {
+			Title string `xml:"title"`
+			ID    string `xml:"id"`
+			Link  struct {
+				Href string `xml:"href,attr"`
+			} `xml:"link"`
+			Content string `xml:"content"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal([]byte(read("feed.xml")), &feed); err != nil {
+		t.Fatalf("Expected a valid feed, got %v", err)
+	}
+	// The second page has no author of its own
+	if feed.Title != "My Blog" || feed.ID != "https://blog.example.com/" || feed.Author.Name != "My Blog" || len(feed.Entries) != 2 {
+		t.Fatalf("Unexpected feed %+v", feed)
+	}
+	entry := feed.Entries[0]
+	if entry.ID != "https://telegra.ph/Second-10-19" || entry.Link.Href != "https://blog.example.com/Second-10-19.html" {
+		t.Errorf("Expected the entry to link to the exported page, got %+v", entry)
+	}
+	for _, want := range []string{`src="https://blog.example.com/assets/cat.png"`, `href="https://blog.example.com/First-10-18.html#intro"`} {
+		if !strings.Contains(entry.Content, want) {
+			t.Errorf("Expected entry content to contain %s, got %s", want, entry.Content)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package telegraph
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// maxPathProbes limits the number of suffixes ResolvePath tries
+const maxPathProbes = 1000
+
+// Transliteration of letters Telegraph maps to Latin when deriving page paths.
+// Only lowercase letters are listed; uppercase letters are capitalized.
+var translit = map[rune]string{
+	// Russian
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "j", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "h", 'ц': "c",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "eh", 'ю': "yu",
+	'я': "ya",
+	// Ukrainian and Belarusian
+	'є': "ye", 'і': "i", 'ї': "yi", 'ґ': "g", 'ў': "u",
+	// Greek
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i", 'θ': "th",
+	'ι': "i", 'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x", 'ο': "o", 'π': "p",
+	'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t", 'υ': "y", 'φ': "f", 'χ': "ch", 'ψ': "ps",
+	'ω': "o", 'ά': "a", 'έ': "e", 'ή': "i", 'ί': "i", 'ό': "o", 'ύ': "y", 'ώ': "o",
+	// Latin with diacritics
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ă': "a",
+	'ą': "a", 'æ': "ae", 'ç': "c", 'ć': "c", 'č': "c", 'ď': "d", 'đ': "d", 'è': "e",
+	'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ę': "e", 'ě': "e", 'ğ': "g", 'ì': "i",
+	'í': "i", 'î': "i", 'ï': "i", 'ī': "i", 'ı': "i", 'ł': "l", 'ñ': "n", 'ń': "n",
+	'ň': "n", 'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o",
+	'ő': "o", 'œ': "oe", 'ř': "r", 'ś': "s", 'š': "s", 'ş': "s", 'ß': "ss", 'ť': "t",
+	'ţ': "t", 'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ů': "u", 'ű': "u",
+	'ý': "y", 'ÿ': "y", 'ź': "z", 'ż': "z", 'ž': "z", 'þ': "th", 'ð': "d",
+}
+
+// Slug returns the title part of the path Telegraph derives for a page with
+// the given title: letters are transliterated to Latin, every run of other
+// characters becomes a single "-" and the case of letters is kept.
+func Slug(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range title {
+		s, ok := slugRune(r)
+		if !ok {
+			dash = b.Len() > 0
+			continue
+		}
+		if s == "" {
+			continue
+		}
+		if dash {
+			b.WriteByte('-')
+			dash = false
+		}
+		b.WriteString(s)
+	}
+	return b.String()
+}
+
+// slugRune returns the Latin spelling of a letter or digit, which is empty
+// for signs such as ъ, or false for characters that separate words
+func slugRune(r rune) (string, bool) {
+	if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+		return string(r), true
+	}
+	s, ok := translit[unicode.ToLower(r)]
+	if ok && s != "" && unicode.IsUpper(r) {
+		s = strings.ToUpper(s[:1]) + s[1:]
+	}
+	return s, ok
+}
+
+// PredictPath returns the path Telegraph gives the first page with the given
+// title created on date, e.g. "Hello-World-10-19". Pages created later with
+// the same title and date get the suffixes "-2", "-3" and so on; use
+// ResolvePath to find the one a new page would get. Telegraph uses the UTC
+// date of creation, so date is converted to UTC first.
+func PredictPath(title string, date time.Time) string {
+	date = date.UTC()
+	suffix := fmt.Sprintf("%02d-%02d", int(date.Month()), date.Day())
+	if slug := Slug(title); slug != "" {
+		return slug + "-" + suffix
+	}
+	return suffix
+}
+
+// ResolvePath returns the path a page with the given title created on date
+// would get, probing the predicted path and its numbered variants with
+// GetPage until one is not taken.
+func (c *Client) ResolvePath(ctx context.Context, title string, date time.Time) (string, error) {
+	base := PredictPath(title, date)
+	for i := 1; i <= maxPathProbes; i++ {
+		path := base
+		if i > 1 {
+			path = fmt.Sprintf("%s-%d", base, i)
+		}
+
+		_, err := c.GetPageWith(ctx, GetPageRequest{Path: path})
+		if IsPageNotFound(err) {
+			return path, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("no free path for %s after %d attempts", base, maxPathProbes)
+}
@@ -0,0 +1,77 @@
+package telegraph_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/smirnoffmg/telegraph"
+)
+
+func TestPredictPath(t *testing.T) {
+	date := time.Date(2024, time.March, 5, 12, 0, 0, 0, time.UTC)
+	tests := map[string]string{
+		"Hello, World!":        "Hello-World-03-05",
+		"Привет, мир":          "Privet-mir-03-05",
+		"Щука и подъезд":       "Shchuka-i-podezd-03-05",
+		"Ελληνικά":             "Ellinika-03-05",
+		"Crème brûlée à Paris": "Creme-brulee-a-Paris-03-05",
+		"  Go 1.22 -- notes ":  "Go-1-22-notes-03-05",
+		"日本語":                  "03-05",
+	}
+
+	for title, want := range tests {
+		if got := telegraph.PredictPath(title, date); got != want {
+			t.Errorf("PredictPath(%q) = %q, want %q", title, got, want)
+		}
+	}
+
+	// Late on March 4th in New York it is already March 5th in UTC
+	local := time.Date(2024, time.March, 4, 23, 30, 0, 0, time.FixedZone("EST", -5*60*60))
+	if got := telegraph.PredictPath("Hello", local); got != "Hello-03-05" {
+		t.Errorf("PredictPath at %v = %q, want %q", local, got, "Hello-03-05")
+	}
+}
+
+func TestResolvePath(t *testing.T) {
+	taken := map[string]bool{"Hello-03-05": true, "Hello-03-05-2": true}
+	var probed []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/getPage/")
+		probed = append(probed, path)
+		response := telegraph.GetPageResponse{Ok: taken[path], Result: telegraph.Page{Path: path}}
+		if !taken[path] {
+			response.Error = "PAGE_NOT_FOUND"
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	client := telegraph.NewClient(server.Client())
+	client.SetBaseURL(server.URL + "/")
+
+	path, err := client.ResolvePath(context.Background(), "Hello", time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if path != "Hello-03-05-3" {
+		t.Errorf("Expected path 'Hello-03-05-3', got '%s'", path)
+	}
+	if len(probed) != 3 {
+		t.Errorf("Expected 3 probes, got %v", probed)
+	}
+
+	// Other errors are returned
+	failing := mockServer(`{"ok":false,"error":"FLOOD_WAIT_5"}`, http.StatusOK)
+	defer failing.Close()
+	client.SetBaseURL(failing.URL + "/")
+	if _, err := client.ResolvePath(context.Background(), "Hello", time.Now()); err == nil || telegraph.IsPageNotFound(err) {
+		t.Errorf("Expected non-404 error, got %v", err)
+	}
+}
@@ -0,0 +1,208 @@
+package telegraph
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// TableStrategy selects how HTML tables are converted to content
+type TableStrategy int
+
+const (
+	// TablesAsText renders tables as aligned monospace text inside pre
+	TablesAsText TableStrategy = iota
+	// TablesAsList renders every row as a list item of "header: value" lines
+	TablesAsList
+	// TablesAsImage renders tables to images with ConvertOptions.TableRenderer
+	TablesAsImage
+)
+
+// Table is the text of an HTML table
+type Table struct {
+	Caption string
+	Header  []string // cells of the header row, if the table has one
+	Rows    [][]string
+}
+
+// TableRenderer renders tables to images for TablesAsImage
+type TableRenderer interface {
+	// RenderTable renders the table to an image, uploads it, e.g. with
+	// Client.Upload, and returns the src of the uploaded image
+	RenderTable(t Table) (string, error)
+}
+
+// table converts a table element according to the table strategy
+func (cv *converter) table(n *html.Node) ([]Node, error) {
+	t := parseTable(n)
+	if len(t.Header) == 0 && len(t.Rows) == 0 {
+		return nil, nil
+	}
+
+	switch cv.opts.Tables {
+	case TablesAsList:
+		return tableList(t), nil
+	case TablesAsImage:
+		if cv.opts.TableRenderer == nil {
+			return nil, ErrNoTableRenderer
+		}
+		src, err := cv.opts.TableRenderer.RenderTable(t)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render table: %w", err)
+		}
+		figure := NodeElement{Tag: "figure", Children: []Node{NodeElement{Tag: "img", Attrs: map[string]string{"src": src}}}}
+		if t.Caption != "" {
+			figure.Children = append(figure.Children, NodeElement{Tag: "figcaption", Children: []Node{t.Caption}})
+		}
+		return []Node{figure}, nil
+	default:
+		return tableText(t), nil
+	}
+}
+
+// parseTable collects the caption and the cell text of a table element.
+// Rows made entirely of th cells before any data row form the header.
+func parseTable(n *html.Node) Table {
+	var t Table
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			switch c.DataAtom {
+			case atom.Caption:
+				t.Caption = cellText(c)
+			case atom.Thead, atom.Tbody, atom.Tfoot:
+				walk(c)
+			case atom.Tr:
+				row, header := tableRow(c)
+				if header && len(t.Header) == 0 && len(t.Rows) == 0 {
+					t.Header = row
+				} else if len(row) > 0 {
+					t.Rows = append(t.Rows, row)
+				}
+			}
+		}
+	}
+	walk(n)
+	return t
+}
+
+// tableRow returns the cell text of a tr element and whether all cells are th
+func tableRow(tr *html.Node) ([]string, bool) {
+	var row []string
+	header := true
+	for c := tr.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || (c.DataAtom != atom.Td && c.DataAtom != atom.Th) {
+			continue
+		}
+		header = header && c.DataAtom == atom.Th
+		row = append(row, cellText(c))
+	}
+	return row, header && len(row) > 0
+}
+
+// cellText returns the whitespace-collapsed text of an HTML node
+func cellText(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		if n.Type == html.ElementNode && n.DataAtom == atom.Br {
+			b.WriteByte(' ')
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return strings.TrimSpace(collapseSpace(b.String()))
+}
+
+// tableText renders the table as aligned columns of monospace text
+func tableText(t Table) []Node {
+	rows := t.Rows
+	if len(t.Header) > 0 {
+		rows = append([][]string{t.Header}, rows...)
+	}
+
+	var widths []int
+	for _, row := range rows {
+		for i, cell := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+
+	var b strings.Builder
+	writeRow := func(row []string) {
+		var line strings.Builder
+		for i, width := range widths {
+			cell := ""
+			if i < len(row) {
+				cell = row[i]
+			}
+			if i > 0 {
+				line.WriteString(" | ")
+			}
+			line.WriteString(cell)
+			line.WriteString(strings.Repeat(" ", width-utf8.RuneCountInString(cell)))
+		}
+		b.WriteString(strings.TrimRight(line.String(), " "))
+		b.WriteByte('\n')
+	}
+
+	for i, row := range rows {
+		writeRow(row)
+		if i == 0 && len(t.Header) > 0 {
+			separators := make([]string, len(widths))
+			for j, width := range widths {
+				separators[j] = strings.Repeat("-", width)
+			}
+			b.WriteString(strings.Join(separators, "-+-"))
+			b.WriteByte('\n')
+		}
+	}
+
+	var nodes []Node
+	if t.Caption != "" {
+		nodes = append(nodes, NodeElement{Tag: "p", Children: []Node{NodeElement{Tag: "b", Children: []Node{t.Caption}}}})
+	}
+	return append(nodes, NodeElement{Tag: "pre", Children: []Node{strings.TrimRight(b.String(), "\n")}})
+}
+
+// tableList renders every row of the table as a list item
+func tableList(t Table) []Node {
+	items := make([]Node, 0, len(t.Rows))
+	for _, row := range t.Rows {
+		var children []Node
+		for i, cell := range row {
+			if i > 0 {
+				children = append(children, NodeElement{Tag: "br"})
+			}
+			if i < len(t.Header) && t.Header[i] != "" {
+				children = append(children, NodeElement{Tag: "b", Children: []Node{t.Header[i] + ":"}}, " "+cell)
+			} else {
+				children = append(children, cell)
+			}
+		}
+		items = append(items, NodeElement{Tag: "li", Children: children})
+	}
+
+	var nodes []Node
+	if t.Caption != "" {
+		nodes = append(nodes, NodeElement{Tag: "p", Children: []Node{NodeElement{Tag: "b", Children: []Node{t.Caption}}}})
+	}
+	if len(items) > 0 {
+		nodes = append(nodes, NodeElement{Tag: "ul", Children: items})
+	}
+	return nodes
+}
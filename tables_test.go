@@ -0,0 +1,86 @@
+package telegraph_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/smirnoffmg/telegraph"
+)
+
+const testTableHTML = `<table>
+<caption>Prices</caption>
+<thead><tr><th>Item</th><th>Price</th></tr></thead>
+<tbody>
+<tr><td>Apple</td><td>1</td></tr>
+<tr><td>Watermelon</td><td>12</td></tr>
+</tbody>
+</table>`
+
+type tableRendererFunc func(t telegraph.Table) (string, error)
+
+func (f tableRendererFunc) RenderTable(t telegraph.Table) (string, error) {
+	return f(t)
+}
+
+func TestConvertHTMLTables(t *testing.T) {
+	var rendered telegraph.Table
+	renderer := tableRendererFunc(func(t telegraph.Table) (string, error) {
+		rendered = t
+		return "/file/table.png", nil
+	})
+
+	tests := []struct {
+		name     string
+		opts     telegraph.ConvertOptions
+		wantJSON string
+	}{
+		{
+			name: "Text",
+			opts: telegraph.ConvertOptions{},
+			wantJSON: `[{"tag":"p","children":[{"tag":"b","children":["Prices"]}]},` +
+				`{"tag":"pre","children":["Item       | Price\n-----------+------\nApple      | 1\nWatermelon | 12"]}]`,
+		},
+		{
+			name: "List",
+			opts: telegraph.ConvertOptions{Tables: telegraph.TablesAsList},
+			wantJSON: `[{"tag":"p","children":[{"tag":"b","children":["Prices"]}]},{"tag":"ul","children":[` +
+				`{"tag":"li","children":[{"tag":"b","children":["Item:"]}," Apple",{"tag":"br"},{"tag":"b","children":["Price:"]}," 1"]},` +
+				`{"tag":"li","children":[{"tag":"b","children":["Item:"]}," Watermelon",{"tag":"br"},{"tag":"b","children":["Price:"]}," 12"]}]}]`,
+		},
+		{
+			name: "Image",
+			opts: telegraph.ConvertOptions{Tables: telegraph.TablesAsImage, TableRenderer: renderer},
+			wantJSON: `[{"tag":"figure","children":[{"tag":"img","attrs":{"src":"/file/table.png"}},` +
+				`{"tag":"figcaption","children":["Prices"]}]}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := telegraph.ConvertHTML(strings.NewReader(testTableHTML), tt.opts)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			got, err := json.Marshal(content)
+			if err != nil {
+				t.Fatalf("Failed to marshal content: %v", err)
+			}
+			if string(got) != tt.wantJSON {
+				t.Errorf("Expected\n%s\ngot\n%s", tt.wantJSON, string(got))
+			}
+		})
+	}
+
+	if len(rendered.Header) != 2 || len(rendered.Rows) != 2 || rendered.Rows[1][0] != "Watermelon" {
+		t.Errorf("Expected renderer to receive the parsed table, got %+v", rendered)
+	}
+}
+
+func TestConvertHTMLTablesWithoutRenderer(t *testing.T) {
+	_, err := telegraph.ConvertHTML(strings.NewReader(testTableHTML), telegraph.ConvertOptions{Tables: telegraph.TablesAsImage})
+	if !errors.Is(err, telegraph.ErrNoTableRenderer) {
+		t.Errorf("Expected ErrNoTableRenderer, got %v", err)
+	}
+}
@@ -5,14 +5,27 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"strings"
+	"time"
 )
 
 // Client represents a client for the Telegraph API.
 type Client struct {
 	httpClient *http.Client
 	baseURL    string
+	uploadURL  string
 	debug      bool
+	hook       func(RequestInfo)
+}
+
+// RequestInfo describes a finished API request for instrumentation.
+type RequestInfo struct {
+	Method     string        // API method, e.g. "createPage" or "upload"
+	StatusCode int           // HTTP status code, 0 if no response was received
+	Duration   time.Duration // time spent on the request including decoding
+	Err        error         // error returned to the caller, if any
 }
 
 // NewClient creates a new Telegraph API client.
@@ -20,6 +33,7 @@ func NewClient(httpClient *http.Client) *Client {
 	return &Client{
 		httpClient: httpClient,
 		baseURL:    "https://api.telegra.ph/",
+		uploadURL:  "https://telegra.ph/upload",
 	}
 }
 
@@ -33,6 +47,16 @@ func (c *Client) BaseURL() string {
 	return c.baseURL
 }
 
+// SetUploadURL sets the URL files are uploaded to.
+func (c *Client) SetUploadURL(uploadURL string) {
+	c.uploadURL = uploadURL
+}
+
+// UploadURL returns the URL files are uploaded to.
+func (c *Client) UploadURL() string {
+	return c.uploadURL
+}
+
 // SetDebug enables or disables debug mode.
 func (c *Client) SetDebug(debug bool) {
 	c.debug = debug
@@ -43,8 +67,52 @@ func (c *Client) Debug() bool {
 	return c.debug
 }
 
+// SetHook sets a function called after every API request, e.g. to record
+// metrics. Pass nil to remove it.
+func (c *Client) SetHook(hook func(RequestInfo)) {
+	c.hook = hook
+}
+
+// call performs an API request and unwraps the Response envelope. Errors,
+// including the error message of responses with ok=false, are wrapped with
+// the failure error of the method.
+func call[T any](ctx context.Context, c *Client, endpoint string, body interface{}, failure error) (*T, error) {
+	start := time.Now()
+	method, _, _ := strings.Cut(endpoint, "/")
+
+	var result Response[T]
+	status, err := c.doRequestStatus(ctx, http.MethodPost, endpoint, body, &result)
+	if err == nil && !result.Ok {
+		err = &APIError{Method: method, StatusCode: status, Message: result.Error}
+	}
+	if err != nil {
+		err = fmt.Errorf("%w: %w", failure, err)
+	}
+	c.observe(method, status, start, err)
+
+	if err != nil {
+		return nil, err
+	}
+	return &result.Result, nil
+}
+
+// observe reports a finished request to the hook
+func (c *Client) observe(method string, status int, start time.Time, err error) {
+	if c.hook != nil {
+		c.hook(RequestInfo{Method: method, StatusCode: status, Duration: time.Since(start), Err: err})
+	}
+}
+
 // doRequest sends a HTTP request to the Telegraph API.
 func (c *Client) doRequest(method, endpoint string, body interface{}, result interface{}) error {
+	_, err := c.doRequestStatus(context.Background(), method, endpoint, body, result)
+	return err
+}
+
+// doRequestStatus sends a HTTP request to the Telegraph API bound to ctx and
+// returns the status code of the response. Error payloads of responses with
+// an unexpected status code are returned as *APIError.
+func (c *Client) doRequestStatus(ctx context.Context, method, endpoint string, body interface{}, result interface{}) (int, error) {
 	url := c.baseURL + endpoint
 
 	var reqBody []byte
@@ -52,14 +120,13 @@ func (c *Client) doRequest(method, endpoint string, body interface{}, result int
 	if body != nil {
 		reqBody, err = json.Marshal(body)
 		if err != nil {
-			return fmt.Errorf("failed to marshal request body: %w", err)
+			return 0, fmt.Errorf("failed to marshal request body: %w", err)
 		}
 	}
 
-	ctx := context.Background()
 	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(reqBody))
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return 0, fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
@@ -69,20 +136,35 @@ func (c *Client) doRequest(method, endpoint string, body interface{}, result int
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to execute request: %w", err)
+		return 0, fmt.Errorf("failed to execute request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return resp.StatusCode, statusError(endpoint, resp)
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
-		return fmt.Errorf("failed to decode response: %w", err)
+		return resp.StatusCode, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	if c.debug {
 		fmt.Printf("Received response: %+v\n", result)
 	}
-	return nil
+	return resp.StatusCode, nil
+}
+
+// statusError returns the error for a response with an unexpected status
+// code, keeping the error message of the response if it has one
+func statusError(endpoint string, resp *http.Response) error {
+	method, _, _ := strings.Cut(endpoint, "/")
+
+	var payload struct {
+		Error string `json:"error"`
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err == nil && json.Unmarshal(data, &payload) == nil && payload.Error != "" {
+		return &APIError{Method: method, StatusCode: resp.StatusCode, Message: payload.Error}
+	}
+	return fmt.Errorf("%w: %d", ErrUnexpectedStatusCode, resp.StatusCode)
 }
@@ -1,10 +1,14 @@
 package telegraph
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math/rand"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 const (
@@ -14,6 +18,7 @@ const (
 	authorName          = "Tester"
 	authorURL           = "https://example.com"
 	accessToken         = "123456"
+	testPath            = "test-path"
 )
 
 func mockServer(response string, statusCode int) *httptest.Server {
@@ -138,3 +143,147 @@ func TestSetDebug(t *testing.T) {
 		t.Fatalf("Expected debug to be true, got false")
 	}
 }
+
+func TestCallErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+	}{
+		{"Error with ok=false", http.StatusOK},
+		{"Error with unexpected status", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := mockServer(testErrorResponse, tt.statusCode)
+			defer server.Close()
+
+			client := NewClient(server.Client())
+			client.SetBaseURL(server.URL + "/")
+
+			var infos []RequestInfo
+			client.SetHook(func(info RequestInfo) {
+				infos = append(infos, info)
+			})
+
+			_, err := call[Page](context.Background(), client, "getPage/"+testPath, nil, ErrGetPageFailed)
+			if !errors.Is(err, ErrGetPageFailed) {
+				t.Errorf("Expected ErrGetPageFailed, got %v", err)
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("Expected APIError, got %v", err)
+			}
+			if apiErr.Method != "getPage" || apiErr.Message != "test error" || apiErr.StatusCode != tt.statusCode {
+				t.Errorf("Unexpected API error %+v", apiErr)
+			}
+
+			if len(infos) != 1 || infos[0].Method != "getPage" || infos[0].StatusCode != tt.statusCode || infos[0].Err == nil {
+				t.Errorf("Expected hook to observe the failed request, got %+v", infos)
+			}
+		})
+	}
+
+	// Unexpected status without an error payload
+	server := mockServer(`Bad Gateway`, http.StatusBadGateway)
+	defer server.Close()
+
+	client := NewClient(server.Client())
+	client.SetBaseURL(server.URL + "/")
+
+	_, err := call[Page](context.Background(), client, "getPage/"+testPath, nil, ErrGetPageFailed)
+	if !errors.Is(err, ErrUnexpectedStatusCode) {
+		t.Errorf("Expected ErrUnexpectedStatusCode, got %v", err)
+	}
+}
+
+func TestPageDates(t *testing.T) {
+	pages := []Page{
+		{Path: "Newest-01-05"},
+		{Path: "Untitled"},
+		{Path: "Older-12-20-2"},
+		{Path: "Same-day-12-20"},
+		{Path: "Oldest-12-31"},
+	}
+	dates := pageDates(pages, time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC))
+
+	want := []string{"2025-01-05", "0001-01-01", "2024-12-20", "2024-12-20", "2023-12-31"}
+	for i, date := range dates {
+		if got := date.Format("2006-01-02"); got != want[i] {
+			t.Errorf("Expected %s to be dated %s, got %s", pages[i].Path, want[i], got)
+		}
+	}
+}
+
+func TestEditScript(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for round := 0; round < 500; round++ {
+		a := make([]int, rng.Intn(30))
+		b := make([]int, rng.Intn(30))
+		for i := range a {
+			a[i] = rng.Intn(4)
+		}
+		for j := range b {
+			b[j] = rng.Intn(4)
+		}
+
+		// The length of the longest common subsequence by the textbook table
+		lengths := make([][]int, len(a)+1)
+		for i := range lengths {
+			lengths[i] = make([]int, len(b)+1)
+		}
+		for i := len(a) - 1; i >= 0; i-- {
+			for j := len(b) - 1; j >= 0; j-- {
+				if a[i] == b[j] {
+					lengths[i][j] = lengths[i+1][j+1] + 1
+				} else {
+					lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+				}
+			}
+		}
+
+		ops := editScript(len(a), len(b), func(i, j int) bool { return a[i] == b[j] })
+		i, j, equal := 0, 0, 0
+		for _, op := range ops {
+			switch op {
+			case opEqual:
+				if i >= len(a) || j >= len(b) || a[i] != b[j] {
+					t.Fatalf("Invalid script %v for %v -> %v", ops, a, b)
+				}
+				i, j, equal = i+1, j+1, equal+1
+			case opDelete:
+				i++
+			case opInsert:
+				j++
+			}
+		}
+		if i != len(a) || j != len(b) || equal != lengths[0][0] {
+			t.Fatalf("Expected a script keeping %d items of %v -> %v, got %v", lengths[0][0], a, b, ops)
+		}
+	}
+}
+
+func TestNodeEqual(t *testing.T) {
+	link := func(attrs map[string]string) NodeElement {
+		return NodeElement{Tag: "a", Attrs: attrs, Children: []Node{"link"}}
+	}
+
+	tests := []struct {
+		name string
+		a, b Node
+		want bool
+	}{
+		{"Empty and missing attribute", link(map[string]string{"href": ""}), link(nil), true},
+		{"Missing and empty attribute", link(nil), link(map[string]string{"href": ""}), true},
+		{"Different attribute", link(map[string]string{"href": "x"}), link(nil), false},
+		{"Same attributes", link(map[string]string{"href": "x"}), link(map[string]string{"href": "x"}), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nodeEqual(tt.a, tt.b); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
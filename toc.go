@@ -0,0 +1,115 @@
+package telegraph
+
+import (
+	"regexp"
+	"strings"
+)
+
+// TOCOptions controls how a table of contents is generated
+type TOCOptions struct {
+	// Title is shown in bold above the list of links, nothing when empty
+	Title string
+	// Depth is the number of heading levels to list: 1 for h3 only, 2 (the
+	// default) for h3 and h4
+	Depth int
+	// Position is the index of the top-level node the table of contents is
+	// inserted before. Positions past the end append it to the content.
+	Position int
+}
+
+var anchorSpace = regexp.MustCompile(`\s+`)
+
+// HeadingAnchor returns the anchor telegra.ph assigns to a heading with the
+// given text, i.e. the text with every run of whitespace replaced by "-"
+func HeadingAnchor(text string) string {
+	return anchorSpace.ReplaceAllString(strings.TrimSpace(text), "-")
+}
+
+// InsertTOC returns a copy of content with a table of contents of its
+// top-level h3 and h4 headings inserted. Every entry links to the heading
+// anchor, so the links jump to the heading when the page is viewed on
+// telegra.ph. Content without headings is returned unchanged.
+func InsertTOC(content []Node, opts TOCOptions) []Node {
+	toc := TableOfContents(content, opts)
+	if toc == nil {
+		return content
+	}
+
+	pos := opts.Position
+	if pos < 0 {
+		pos = 0
+	}
+	if pos > len(content) {
+		pos = len(content)
+	}
+
+	out := make([]Node, 0, len(content)+len(toc))
+	out = append(out, content[:pos]...)
+	out = append(out, toc...)
+	return append(out, content[pos:]...)
+}
+
+// TableOfContents returns the nodes of a table of contents for content,
+// or nil when it has no headings
+func TableOfContents(content []Node, opts TOCOptions) []Node {
+	depth := opts.Depth
+	if depth <= 0 {
+		depth = 2
+	}
+
+	var entries []Node
+	var sub []Node
+	flush := func() {
+		if len(sub) == 0 {
+			return
+		}
+		list := NodeElement{Tag: "ul", Children: sub}
+		if last := len(entries) - 1; last >= 0 {
+			item := entries[last].(NodeElement)
+			item.Children = append(item.Children, list)
+			entries[last] = item
+		} else {
+			entries = append(entries, list.Children...)
+		}
+		sub = nil
+	}
+
+	for _, n := range content {
+		elem, ok := asElement(n)
+		if !ok {
+			continue
+		}
+		text := strings.TrimSpace(collapseSpace(nodeText(elem)))
+		if text == "" {
+			continue
+		}
+		switch {
+		case elem.Tag == "h3":
+			flush()
+			entries = append(entries, tocEntry(text))
+		case elem.Tag == "h4" && depth > 1:
+			sub = append(sub, tocEntry(text))
+		}
+	}
+	flush()
+
+	if len(entries) == 0 {
+		return nil
+	}
+
+	var toc []Node
+	if opts.Title != "" {
+		toc = append(toc, NodeElement{Tag: "p", Children: []Node{NodeElement{Tag: "b", Children: []Node{opts.Title}}}})
+	}
+	return append(toc, NodeElement{Tag: "ul", Children: entries})
+}
+
+// tocEntry returns a list item linking to the heading with the given text
+func tocEntry(text string) NodeElement {
+	return NodeElement{
+		Tag: "li",
+		Children: []Node{
+			NodeElement{Tag: "a", Attrs: map[string]string{"href": "#" + HeadingAnchor(text)}, Children: []Node{text}},
+		},
+	}
+}
@@ -0,0 +1,71 @@
+package telegraph_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/smirnoffmg/telegraph"
+)
+
+func TestHeadingAnchor(t *testing.T) {
+	tests := map[string]string{
+		"Getting started":        "Getting-started",
+		"  Spaces \t and\ntabs ": "Spaces-and-tabs",
+		"Установка":              "Установка",
+	}
+	for text, want := range tests {
+		if got := telegraph.HeadingAnchor(text); got != want {
+			t.Errorf("HeadingAnchor(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestInsertTOC(t *testing.T) {
+	content := []telegraph.Node{
+		telegraph.NodeElement{Tag: "p", Children: []telegraph.Node{"Intro"}},
+		telegraph.NodeElement{Tag: "h3", Children: []telegraph.Node{"Install"}},
+		telegraph.NodeElement{Tag: "h4", Children: []telegraph.Node{"On ", telegraph.NodeElement{Tag: "b", Children: []telegraph.Node{"Linux"}}}},
+		telegraph.NodeElement{Tag: "h3", Children: []telegraph.Node{"Usage"}},
+	}
+
+	tests := []struct {
+		name     string
+		opts     telegraph.TOCOptions
+		wantJSON string
+	}{
+		{
+			name: "Nested headings with title",
+			opts: telegraph.TOCOptions{Title: "Contents", Position: 1},
+			wantJSON: `[{"tag":"p","children":["Intro"]},{"tag":"p","children":[{"tag":"b","children":["Contents"]}]},` +
+				`{"tag":"ul","children":[{"tag":"li","children":[{"tag":"a","attrs":{"href":"#Install"},"children":["Install"]},` +
+				`{"tag":"ul","children":[{"tag":"li","children":[{"tag":"a","attrs":{"href":"#On-Linux"},"children":["On Linux"]}]}]}]},` +
+				`{"tag":"li","children":[{"tag":"a","attrs":{"href":"#Usage"},"children":["Usage"]}]}]},` +
+				`{"tag":"h3","children":["Install"]},{"tag":"h4","children":["On ",{"tag":"b","children":["Linux"]}]},{"tag":"h3","children":["Usage"]}]`,
+		},
+		{
+			name: "Top level only",
+			opts: telegraph.TOCOptions{Depth: 1, Position: 100},
+			wantJSON: `[{"tag":"p","children":["Intro"]},{"tag":"h3","children":["Install"]},` +
+				`{"tag":"h4","children":["On ",{"tag":"b","children":["Linux"]}]},{"tag":"h3","children":["Usage"]},` +
+				`{"tag":"ul","children":[{"tag":"li","children":[{"tag":"a","attrs":{"href":"#Install"},"children":["Install"]}]},` +
+				`{"tag":"li","children":[{"tag":"a","attrs":{"href":"#Usage"},"children":["Usage"]}]}]}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(telegraph.InsertTOC(content, tt.opts))
+			if err != nil {
+				t.Fatalf("Failed to marshal content: %v", err)
+			}
+			if string(got) != tt.wantJSON {
+				t.Errorf("Expected\n%s\ngot\n%s", tt.wantJSON, string(got))
+			}
+		})
+	}
+
+	plain := []telegraph.Node{telegraph.NodeElement{Tag: "p", Children: []telegraph.Node{"No headings"}}}
+	if got := telegraph.InsertTOC(plain, telegraph.TOCOptions{Title: "Contents"}); len(got) != 1 {
+		t.Errorf("Expected content without headings to be unchanged, got %d nodes", len(got))
+	}
+}
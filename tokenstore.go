@@ -0,0 +1,190 @@
+package telegraph
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// TokenStore persists access tokens of named accounts
+type TokenStore interface {
+	// Load returns the token stored under name or ErrTokenNotFound
+	Load(name string) (string, error)
+	// Save stores the token under name, replacing any previous token
+	Save(name, token string) error
+	// Delete removes the token stored under name
+	Delete(name string) error
+	// Names returns the names of all stored tokens in sorted order
+	Names() ([]string, error)
+}
+
+// FileTokenStore is a TokenStore keeping tokens in a JSON file, optionally encrypted
+type FileTokenStore struct {
+	path string
+	aead cipher.AEAD
+	mu   sync.Mutex
+}
+
+// NewFileTokenStore creates a token store kept in a plain JSON file at path
+func NewFileTokenStore(path string) *FileTokenStore {
+	return &FileTokenStore{path: path}
+}
+
+// NewEncryptedFileTokenStore creates a token store kept in a file at path
+// encrypted with AES-GCM. The key must be 16, 24 or 32 bytes long.
+func NewEncryptedFileTokenStore(path string, key []byte) (*FileTokenStore, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return &FileTokenStore{path: path, aead: aead}, nil
+}
+
+// Load returns the token stored under name
+func (s *FileTokenStore) Load(name string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return "", err
+	}
+	token, ok := tokens[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrTokenNotFound, name)
+	}
+	return token, nil
+}
+
+// Save stores the token under name. The file is replaced atomically.
+func (s *FileTokenStore) Save(name, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return err
+	}
+	tokens[name] = token
+	return s.write(tokens)
+}
+
+// Delete removes the token stored under name
+func (s *FileTokenStore) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := tokens[name]; !ok {
+		return nil
+	}
+	delete(tokens, name)
+	return s.write(tokens)
+}
+
+// Names returns the names of all stored tokens
+func (s *FileTokenStore) Names() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(tokens))
+	for name := range tokens {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// read loads all tokens, treating a missing file as an empty store
+func (s *FileTokenStore) read() (map[string]string, error) {
+	tokens := make(map[string]string)
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return tokens, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token store: %w", err)
+	}
+
+	if s.aead != nil {
+		size := s.aead.NonceSize()
+		if len(data) < size {
+			return nil, fmt.Errorf("failed to decrypt token store: %w", ErrInvalidTokenStore)
+		}
+		data, err = s.aead.Open(nil, data[:size], data[size:], nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt token store: %w", ErrInvalidTokenStore)
+		}
+	}
+
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to decode token store: %w", err)
+	}
+	return tokens, nil
+}
+
+// write replaces the file with the given tokens through a temporary file,
+// so readers never observe a partially written store
+func (s *FileTokenStore) write(tokens map[string]string) error {
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode token store: %w", err)
+	}
+
+	if s.aead != nil {
+		nonce := make([]byte, s.aead.NonceSize())
+		if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+			return fmt.Errorf("failed to generate nonce: %w", err)
+		}
+		data = s.aead.Seal(nonce, nonce, data, nil)
+	}
+
+	return WriteFileAtomic(s.path, data)
+}
+
+// WriteFileAtomic writes data to a temporary file next to path and renames
+// it over path, so that readers never see the file half written. Missing
+// directories are created.
+func WriteFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
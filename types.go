@@ -12,9 +12,32 @@ type Account struct {
 	AuthorName  string `json:"author_name"`
 	AuthorURL   string `json:"author_url"`
 	AccessToken string `json:"access_token"`
+	AuthURL     string `json:"auth_url,omitempty"`
 	PageCount   int    `json:"page_count"`
 }
 
+// AccountField is an Account field that can be requested with GetAccountInfo
+// See https://telegra.ph/api#getAccountInfo
+type AccountField string
+
+// Fields accepted by getAccountInfo
+const (
+	FieldShortName  AccountField = "short_name"
+	FieldAuthorName AccountField = "author_name"
+	FieldAuthorURL  AccountField = "author_url"
+	FieldAuthURL    AccountField = "auth_url"
+	FieldPageCount  AccountField = "page_count"
+)
+
+// Valid reports whether the field is accepted by getAccountInfo
+func (f AccountField) Valid() bool {
+	switch f {
+	case FieldShortName, FieldAuthorName, FieldAuthorURL, FieldAuthURL, FieldPageCount:
+		return true
+	}
+	return false
+}
+
 // Node represents a content node which can be a string (text node) or a NodeElement
 // See https://telegra.ph/api#Node
 type Node interface{}
@@ -108,58 +131,50 @@ type PageViews struct {
 	Views int    `json:"views"`
 }
 
-// CreateAccountResponse represents the response from the createAccount method
-// See https://telegra.ph/api#createAccount
-type CreateAccountResponse struct {
-	Ok     bool    `json:"ok"`
-	Result Account `json:"result"`
+// AccountPatch holds the account fields to change with PatchAccountInfo.
+// Nil fields are not sent and keep their current values.
+type AccountPatch struct {
+	ShortName  *string `json:"short_name,omitempty"`
+	AuthorName *string `json:"author_name,omitempty"`
+	AuthorURL  *string `json:"author_url,omitempty"`
 }
 
-// CreatePageResponse represents the response from the createPage method
-// See https://telegra.ph/api#createPage
-type CreatePageResponse struct {
-	Ok     bool `json:"ok"`
-	Result Page `json:"result"`
+// PagePatch holds the page fields to change with PatchPage.
+// Nil fields keep their current values. Content cannot be emptied: pages
+// need content, so an empty non-nil Content is an error.
+type PagePatch struct {
+	Title      *string `json:"title,omitempty"`
+	Content    []Node  `json:"content,omitempty"`
+	AuthorName *string `json:"author_name,omitempty"`
+	AuthorURL  *string `json:"author_url,omitempty"`
 }
 
-// GetAccountInfoResponse represents the response from the getAccountInfo method
-// See https://telegra.ph/api#getAccountInfo
-type GetAccountInfoResponse struct {
-	Ok     bool    `json:"ok"`
-	Result Account `json:"result"`
+// String returns a pointer to s for use in patches and requests
+func String(s string) *string {
+	return &s
 }
 
-// EditAccountInfoResponse represents the response from the editAccountInfo method
-// See https://telegra.ph/api#editAccountInfo
-type EditAccountInfoResponse struct {
-	Ok     bool    `json:"ok"`
-	Result Account `json:"result"`
+// Int returns a pointer to i for use in requests
+func Int(i int) *int {
+	return &i
 }
 
-// RevokeAccessTokenResponse represents the response from the revokeAccessToken method
-// See https://telegra.ph/api#revokeAccessToken
-type RevokeAccessTokenResponse struct {
-	Ok     bool    `json:"ok"`
-	Result Account `json:"result"`
+// Response is the envelope of every Telegraph API response
+// See https://telegra.ph/api
+type Response[T any] struct {
+	Ok     bool   `json:"ok"`
+	Result T      `json:"result"`
+	Error  string `json:"error,omitempty"`
 }
 
-// GetPageResponse represents the response from the getPage method
-// See https://telegra.ph/api#getPage
-type GetPageResponse struct {
-	Ok     bool `json:"ok"`
-	Result Page `json:"result"`
-}
-
-// GetPageListResponse represents the response from the getPageList method
-// See https://telegra.ph/api#getPageList
-type GetPageListResponse struct {
-	Ok     bool     `json:"ok"`
-	Result PageList `json:"result"`
-}
-
-// GetViewsResponse represents the response from the getViews method
-// See https://telegra.ph/api#getViews
-type GetViewsResponse struct {
-	Ok     bool      `json:"ok"`
-	Result PageViews `json:"result"`
-}
+// Responses of the individual API methods
+type (
+	CreateAccountResponse     = Response[Account]
+	GetAccountInfoResponse    = Response[Account]
+	EditAccountInfoResponse   = Response[Account]
+	RevokeAccessTokenResponse = Response[Account]
+	CreatePageResponse        = Response[Page]
+	GetPageResponse           = Response[Page]
+	GetPageListResponse       = Response[PageList]
+	GetViewsResponse          = Response[PageViews]
+)
@@ -0,0 +1,121 @@
+package telegraph
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// MaxUploadSize is the largest file Telegraph accepts for upload
+const MaxUploadSize = 5 << 20
+
+// Content types Telegraph accepts for upload
+var uploadTypes = map[string]struct{}{
+	"image/gif":  {},
+	"image/jpeg": {},
+	"image/png":  {},
+	"video/mp4":  {},
+}
+
+// Upload uploads a file read from r to Telegraph and returns its src, such
+// as "/file/6a5b15e7eb4d7329ca7af.jpg", for use in img and video elements.
+// Telegraph accepts JPEG, PNG, GIF and MP4 files of up to MaxUploadSize bytes.
+func (c *Client) Upload(ctx context.Context, name string, r io.Reader) (string, error) {
+	start := time.Now()
+	src, status, err := c.upload(ctx, name, r)
+	if err != nil {
+		err = fmt.Errorf("%w: %w", ErrUploadFailed, err)
+	}
+	c.observe("upload", status, start, err)
+	return src, err
+}
+
+// UploadFile uploads the file at path to Telegraph and returns its src
+func (c *Client) UploadFile(ctx context.Context, path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrUploadFailed, err)
+	}
+	defer f.Close()
+
+	return c.Upload(ctx, filepath.Base(path), f)
+}
+
+func (c *Client) upload(ctx context.Context, name string, r io.Reader) (string, int, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxUploadSize+1))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read file: %w", err)
+	}
+	if len(data) > MaxUploadSize {
+		return "", 0, fmt.Errorf("file is larger than %d bytes", MaxUploadSize)
+	}
+
+	contentType := http.DetectContentType(data)
+	if _, ok := uploadTypes[contentType]; !ok {
+		return "", 0, fmt.Errorf("unsupported file type %s", contentType)
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, name))
+	header.Set("Content-Type", contentType)
+	part, err := form.CreatePart(header)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create form: %w", err)
+	}
+	if _, err := part.Write(data); err != nil {
+		return "", 0, fmt.Errorf("failed to create form: %w", err)
+	}
+	if err := form.Close(); err != nil {
+		return "", 0, fmt.Errorf("failed to create form: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.uploadURL, &body)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	if c.debug {
+		fmt.Printf("Uploading %s (%s, %d bytes) to %s\n", name, contentType, len(data), c.uploadURL)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", resp.StatusCode, statusError("upload", resp)
+	}
+
+	// The upload endpoint answers with a list of files or an error object
+	// instead of the usual envelope
+	payload, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", resp.StatusCode, fmt.Errorf("failed to read response: %w", err)
+	}
+	var files []struct {
+		Src string `json:"src"`
+	}
+	if err := json.Unmarshal(payload, &files); err == nil && len(files) > 0 && files[0].Src != "" {
+		return files[0].Src, resp.StatusCode, nil
+	}
+	var failure struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(payload, &failure); err != nil {
+		return "", resp.StatusCode, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return "", resp.StatusCode, &APIError{Method: "upload", StatusCode: resp.StatusCode, Message: failure.Error}
+}
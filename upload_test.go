@@ -0,0 +1,75 @@
+package telegraph_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/smirnoffmg/telegraph"
+)
+
+var testPNG = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x06\x00\x00\x00")
+
+func TestUpload(t *testing.T) {
+	var contentType string
+	var uploaded []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		contentType = header.Header.Get("Content-Type")
+		uploaded, _ = io.ReadAll(file)
+		if _, err := w.Write([]byte(`[{"src":"/file/abc.png"}]`)); err != nil {
+			http.Error(w, "Failed to write response", http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	client := telegraph.NewClient(server.Client())
+	client.SetUploadURL(server.URL + "/upload")
+
+	imagePath := filepath.Join(t.TempDir(), "image.png")
+	if err := os.WriteFile(imagePath, testPNG, 0o600); err != nil {
+		t.Fatalf("Failed to write image: %v", err)
+	}
+
+	src, err := client.UploadFile(context.Background(), imagePath)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if src != "/file/abc.png" {
+		t.Errorf("Expected src '/file/abc.png', got '%s'", src)
+	}
+	if contentType != "image/png" || !bytes.Equal(uploaded, testPNG) {
+		t.Errorf("Expected PNG to be uploaded, got %s with %d bytes", contentType, len(uploaded))
+	}
+
+	// Test unsupported file type
+	_, err = client.Upload(context.Background(), "notes.txt", bytes.NewReader([]byte("plain text")))
+	if !errors.Is(err, telegraph.ErrUploadFailed) {
+		t.Errorf("Expected ErrUploadFailed, got %v", err)
+	}
+}
+
+func TestUploadError(t *testing.T) {
+	server := mockServer(`{"error":"File type invalid"}`, http.StatusOK)
+	defer server.Close()
+
+	client := telegraph.NewClient(server.Client())
+	client.SetUploadURL(server.URL + "/upload")
+
+	_, err := client.Upload(context.Background(), "image.png", bytes.NewReader(testPNG))
+	var apiErr *telegraph.APIError
+	if !errors.As(err, &apiErr) || apiErr.Message != "File type invalid" {
+		t.Errorf("Expected API error 'File type invalid', got %v", err)
+	}
+}